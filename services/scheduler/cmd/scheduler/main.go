@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -14,15 +17,42 @@ import (
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/db"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/dispatcher"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/handler/http"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/notification"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/oidc"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/postgres"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/sqlite"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/retention"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/scheduler"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/validator"
+	"gorm.io/gorm"
 )
 
 var shutDownWg sync.WaitGroup
 
+var resetDB = flag.Bool("reset-db", false, "drop all tables before migrating, destroys all data (development only)")
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  %[1]s [flags]                  run the scheduler, applying pending migrations
+  %[1]s [flags] migrate up       apply all pending migrations
+  %[1]s [flags] migrate down [n] revert the last n migrations (default 1)
+  %[1]s [flags] migrate status   list migrations and whether they are applied
+  %[1]s [flags] apikey create <name> <role> [tenant]
+                                 create an API key with role admin, operator, read_only or worker
+                                 for the jobs of tenant (default "default")
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
 func main() {
+	flag.Usage = usage
+	flag.Parse()
+
 	// Context that cancels on SIGINT or SIGTERM
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -32,32 +62,120 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Printf("Loaded config: %+v\n", cfg)
+	fmt.Printf("Loaded config: %+v\n", cfg.Redacted())
 
 	gormDB, err := db.Connect(&cfg.DB)
 	if err != nil {
 		panic(err)
 	}
 
-	// reset db
-	if err := db.Reset(gormDB); err != nil {
+	if *resetDB {
+		log.Println("[WARN] resetting database, all data is dropped")
+		if err := db.Reset(gormDB); err != nil {
+			panic(err)
+		}
+	}
+
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		panic(err)
+	}
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(migrator, flag.Args()[1:]); err != nil {
+			log.Fatalf("[ERROR] %v\n", err)
+		}
+		return
+	}
+	if flag.NArg() > 0 && flag.Arg(0) != "apikey" {
+		usage()
+		os.Exit(2)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		panic(err)
+	}
+
+	apiKeySvc := service.NewAPIKeyService(postgres.NewAPIKeyRepository(gormDB))
+	if flag.Arg(0) == "apikey" {
+		if err := runAPIKey(ctx, apiKeySvc, flag.Args()[1:]); err != nil {
+			log.Fatalf("[ERROR] %v\n", err)
+		}
+		return
+	}
+
+	dialect := newDialect(cfg.DB.Driver)
+	repo := postgres.New(gormDB, dialect)
+	priceRepo := newPriceRepository(cfg.DB.Driver, gormDB)
+	alertRepo := postgres.NewAlertRepository(gormDB, dialect)
+	deliveryRepo := postgres.NewDeliveryRepository(gormDB, dialect)
+	intervalRepo := postgres.NewIntervalChangeRepository(gormDB, dialect)
+	runRepo := postgres.NewRunRepository(gormDB)
+	auditRepo := postgres.NewAuditRepository(gormDB, dialect)
+	webhookRepo := postgres.NewWebhookRepository(gormDB, dialect)
+
+	notifier, err := notification.NewNotifier(&cfg.Notifications, deliveryRepo)
+	if err != nil {
 		panic(err)
 	}
-	if err := db.AutoMigrate(gormDB); err != nil {
+
+	webhooks := notification.NewWebhookDeliverer(&cfg.Webhooks, webhookRepo)
+
+	// scheduled and manual runs are submitted to the same worker queue
+	workerQueue := dispatcher.NewLogDispatcher()
+
+	quotas, err := tenantQuotas(&cfg.Tenants)
+	if err != nil {
 		panic(err)
 	}
 
-	repo := postgres.New(gormDB)
-	jobSvc := service.NewJobService(repo)
+	auditSvc := service.NewAuditService(auditRepo)
+	eventSvc := service.NewEventService(cfg.Events.BufferSize, webhooks)
+	jobSvc := service.NewJobService(repo, intervalRepo, runRepo, auditSvc, eventSvc, quotas)
+	runSvc := service.NewRunService(repo, runRepo, workerQueue, auditSvc, eventSvc)
+	bulkSvc := service.NewBulkService(repo, jobSvc)
+	priceSvc := service.NewPriceService(repo, priceRepo)
+	alertSvc := service.NewAlertService(repo, alertRepo, deliveryRepo, notifier)
+	webhookSvc := service.NewWebhookService(webhookRepo)
+	resultSvc := service.NewResultService(repo, priceRepo, intervalRepo, runRepo, alertSvc, auditSvc, eventSvc, cfg.Scheduler.MaxRetryAttempts)
+
 	jobHandler := http.NewJobHandler(jobSvc)
+	runHandler := http.NewRunHandler(runSvc)
+	bulkHandler := http.NewBulkHandler(bulkSvc)
+	priceHandler := http.NewPriceHandler(priceSvc)
+	resultHandler := http.NewResultHandler(resultSvc)
+	alertHandler := http.NewAlertHandler(alertSvc)
+	auditHandler := http.NewAuditHandler(auditSvc)
+	eventHandler := http.NewEventHandler(eventSvc, cfg.Events.Heartbeat)
+	webhookHandler := http.NewWebhookHandler(webhookSvc)
+	apiKeyHandler := http.NewAPIKeyHandler(apiKeySvc)
+
+	// bearer tokens are only accepted if OIDC is configured
+	var tokens http.TokenAuthenticator
+	if cfg.Auth.OIDC.Enabled {
+		verifier, err := oidc.NewVerifier(&cfg.Auth.OIDC)
+		if err != nil {
+			panic(err)
+		}
+		tokens = verifier
+	}
 
-	r := http.SetupRouter(jobHandler)
+	r := http.SetupRouter(cfg.Server.RequestTimeout, http.Authenticate(cfg.Auth.Enabled, apiKeySvc, tokens), jobHandler, runHandler, bulkHandler, priceHandler, resultHandler, alertHandler, auditHandler, eventHandler, webhookHandler, apiKeyHandler)
 	validator.RegisterValidators()
 	// register application middleware
 
-	scheduler := scheduler.NewScheduler(&cfg.Scheduler, repo, dispatcher.NewLogDispatcher())
+	scheduler := scheduler.NewScheduler(&cfg.Scheduler, repo, runRepo, auditSvc, eventSvc, workerQueue)
 
-	StartScheduler(ctx, scheduler)
+	// the scheduler and the purger process the jobs of all tenants
+	StartScheduler(repository.AllTenants(ctx), scheduler)
+	StartNotifier(ctx, notifier)
+	StartWebhooks(ctx, webhooks)
+	// price observations are only partitioned on postgres
+	var partitionRepo retention.PartitionRepository
+	if cfg.DB.Driver != config.DriverSQLite {
+		partitionRepo = postgres.NewPartitionRepository(gormDB)
+	}
+	StartPurger(repository.AllTenants(ctx), retention.NewPurger(&cfg.Retention, postgres.NewRetentionRepository(gormDB, dialect), partitionRepo))
 
 	// start api server
 	StartAPI(ctx, r, cfg.Server.Port)
@@ -67,6 +185,113 @@ func main() {
 	GracefulShutdown(cfg.Server.ShutdownTimeoutSeconds)
 }
 
+// runMigrate executes the migrate subcommand with args, e.g. ["down", "2"].
+func runMigrate(migrator *db.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command, expected up, down or status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] %d migration(s) applied\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations to revert: %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] %d migration(s) reverted\n", reverted)
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	return nil
+}
+
+// runAPIKey executes the apikey subcommand with args, e.g. ["create", "ci", "operator", "team-a"].
+// It allows creating the first admin key of a tenant, all further keys of the tenant can be managed through the API.
+func runAPIKey(ctx context.Context, svc *service.APIKeyService, args []string) error {
+	if (len(args) != 3 && len(args) != 4) || args[0] != "create" {
+		return fmt.Errorf("expected apikey create <name> <role> [tenant]")
+	}
+
+	role := model.Role(args[2])
+	if !role.IsValid() {
+		return fmt.Errorf("invalid role %q, expected admin, operator, read_only or worker", args[2])
+	}
+
+	req := &model.CreateAPIKeyRequest{Name: args[1], Role: role, Tenant: model.DefaultTenant}
+	if len(args) == 4 {
+		req.Tenant = args[3]
+		if !model.IsValidTenant(req.Tenant) {
+			return fmt.Errorf("invalid tenant %q, expected up to 64 lowercase letters, digits, dots, underscores or dashes", req.Tenant)
+		}
+	}
+	// keys are issued for the tenant the context is scoped to
+	ctx = repository.WithTenant(ctx, req.Tenant)
+
+	key, err := svc.CreateKey(ctx, req)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] created api key %d %q with role %s for tenant %q, it is not shown again\n", key.ID, key.Name, key.Role, key.Tenant)
+	fmt.Println(key.Key)
+	return nil
+}
+
+// tenantQuotas returns the configured quotas of the tenants.
+func tenantQuotas(cfg *config.TenantsConfig) (*model.TenantQuotas, error) {
+	quotas := &model.TenantQuotas{
+		Default: model.TenantQuota{MaxJobs: cfg.Default.MaxJobs, MinInterval: cfg.Default.MinInterval},
+		Tenants: map[string]model.TenantQuota{},
+	}
+	for tenant, quota := range cfg.Quotas {
+		if !model.IsValidTenant(tenant) {
+			return nil, fmt.Errorf("invalid tenant %q in tenants.quotas", tenant)
+		}
+		quotas.Tenants[tenant] = model.TenantQuota{MaxJobs: quota.MaxJobs, MinInterval: quota.MinInterval}
+	}
+	return quotas, nil
+}
+
+// newDialect returns the dialect of the configured driver, the repositories of the postgres package run on both databases.
+func newDialect(driver string) postgres.Dialect {
+	if driver == config.DriverSQLite {
+		return sqlite.Dialect
+	}
+	return postgres.Postgres
+}
+
+// newPriceRepository returns the price repository of the configured driver, only postgres maintains daily rollups.
+func newPriceRepository(driver string, gormDB *gorm.DB) service.PriceRepository {
+	if driver == config.DriverSQLite {
+		return sqlite.NewPriceRepository(gormDB)
+	}
+	return postgres.NewPriceRepository(gormDB)
+}
+
 func StartScheduler(ctx context.Context, scheduler *scheduler.Scheduler) {
 	shutDownWg.Add(1)
 	go func() {
@@ -75,6 +300,30 @@ func StartScheduler(ctx context.Context, scheduler *scheduler.Scheduler) {
 	}()
 }
 
+func StartNotifier(ctx context.Context, notifier *notification.Notifier) {
+	shutDownWg.Add(1)
+	go func() {
+		defer shutDownWg.Done()
+		notifier.Run(ctx)
+	}()
+}
+
+func StartWebhooks(ctx context.Context, webhooks *notification.WebhookDeliverer) {
+	shutDownWg.Add(1)
+	go func() {
+		defer shutDownWg.Done()
+		webhooks.Run(ctx)
+	}()
+}
+
+func StartPurger(ctx context.Context, purger *retention.Purger) {
+	shutDownWg.Add(1)
+	go func() {
+		defer shutDownWg.Done()
+		purger.Run(ctx)
+	}()
+}
+
 func StartAPI(ctx context.Context, ginEngine *gin.Engine, port int) {
 	//shutDownWg.Add(1)
 	go func() {
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -8,18 +9,33 @@ import (
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Connect opens the database selected by cfg.Driver.
+// If cfg.Replicas are set, reads allowed by repository.WithReplicaReads are
+// routed to the replicas, falling back to the primary if a replica fails.
 func Connect(cfg *config.DBConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=UTC",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode,
-	)
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case config.DriverPostgres, "":
+		dsn := fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=UTC",
+			cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode,
+		)
+		dialector = postgres.Open(dsn)
+	case config.DriverSQLite:
+		// wait for locks instead of failing immediately and enforce foreign keys
+		dialector = sqlite.Open(cfg.Path + "?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL")
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %q", cfg.Driver)
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Warn),
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Warn),
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -30,29 +46,74 @@ func Connect(cfg *config.DBConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
 	}
 
-	sqlDB.SetMaxOpenConns(10)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	if cfg.Driver == config.DriverSQLite {
+		// SQLite allows a single writer, serialize access instead of failing with SQLITE_BUSY
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxOpenConns(10)
+		sqlDB.SetMaxIdleConns(5)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+	}
 
 	// Verify connection
 	if err := sqlDB.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Connected to PostgreSQL successfully")
+	log.Printf("Connected to %s successfully\n", db.Dialector.Name())
+
+	if len(cfg.Replicas) > 0 {
+		if err := useReplicas(db, sqlDB, cfg); err != nil {
+			return nil, err
+		}
+	}
 	return db, nil
 }
 
-func Reset(db *gorm.DB) error {
-	if err := db.Migrator().DropTable(&model.Job{}); err != nil {
-		return fmt.Errorf("failed to rested db: %w", err)
+// useReplicas routes the read queries of db to the replicas of cfg.
+// Replicas are not pinged, unreachable replicas are skipped when queried.
+func useReplicas(db *gorm.DB, primary *sql.DB, cfg *config.DBConfig) error {
+	if cfg.Driver == config.DriverSQLite {
+		log.Println("[WARN] read replicas are not supported by sqlite and ignored")
+		return nil
 	}
+
+	replicas := make([]*sql.DB, 0, len(cfg.Replicas))
+	for i, dsn := range cfg.Replicas {
+		replica, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			return fmt.Errorf("failed to open read replica %d: %w", i, err)
+		}
+		sqlDB, err := replica.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get sql.DB of read replica %d: %w", i, err)
+		}
+		sqlDB.SetMaxOpenConns(10)
+		sqlDB.SetMaxIdleConns(5)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+		replicas = append(replicas, sqlDB)
+	}
+
+	pool := newRoutingPool(primary, replicas)
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+
+	log.Printf("Routing reads to %d read replica(s)\n", len(replicas))
 	return nil
 }
 
-func AutoMigrate(db *gorm.DB) error {
-	if err := db.AutoMigrate(&model.Job{}); err != nil {
-		return fmt.Errorf("failed to auto migrate job table: %w", err)
+// Reset drops all tables including the migration history.
+// It destroys all data and is meant for development only.
+func Reset(db *gorm.DB) error {
+	tables := []any{
+		&model.Job{}, &model.PriceObservation{}, &model.AlertRule{}, &model.AlertEvent{},
+		&model.NotificationDelivery{}, &model.IntervalChange{}, &model.JobRun{}, &model.AuditEntry{},
+		&model.WebhookSubscription{}, &model.WebhookDelivery{}, &model.APIKey{}, &schemaMigration{},
+		// rollups of price observations, only maintained on postgres
+		"price_daily",
+	}
+	if err := db.Migrator().DropTable(tables...); err != nil {
+		return fmt.Errorf("failed to reset db: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS interval_changes;
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS alert_events;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS price_observations;
+DROP TABLE IF EXISTS jobs;
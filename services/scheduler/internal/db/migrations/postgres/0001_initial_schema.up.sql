@@ -0,0 +1,101 @@
+-- Initial schema. Tables are created only if missing, so databases
+-- previously created by GORM AutoMigrate are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id                  BIGSERIAL PRIMARY KEY,
+    url                 TEXT NOT NULL,
+    retry_attempts      BIGINT DEFAULT 0,
+    status              VARCHAR(20) NOT NULL,
+    tags                JSONB NOT NULL DEFAULT '[]',
+    "interval"          BIGINT,
+    pause_requested     BOOLEAN,
+    adaptive            BOOLEAN,
+    min_interval        BIGINT,
+    max_interval        BIGINT,
+    stable_runs         BIGINT DEFAULT 0,
+    interval_reason     VARCHAR(20),
+    interval_changed_at TIMESTAMPTZ,
+    dispatched_at       TIMESTAMPTZ,
+    next_run_at         TIMESTAMPTZ,
+    etag                VARCHAR(255),
+    last_modified       VARCHAR(64),
+    content_hash        VARCHAR(64),
+    not_modified_count  BIGINT DEFAULT 0,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ,
+    CONSTRAINT chk_jobs_retry_attempts CHECK (retry_attempts >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_url ON jobs (url);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_next_run_at ON jobs (status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS price_observations (
+    id           BIGSERIAL PRIMARY KEY,
+    job_id       BIGINT NOT NULL,
+    observed_at  TIMESTAMPTZ NOT NULL,
+    amount       BIGINT NOT NULL,
+    currency     CHAR(3) NOT NULL,
+    availability VARCHAR(20) NOT NULL,
+    snippet_hash VARCHAR(128),
+    created_at   TIMESTAMPTZ,
+    CONSTRAINT chk_price_observations_amount CHECK (amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_observations_job_observed ON price_observations (job_id, observed_at);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id         BIGSERIAL PRIMARY KEY,
+    job_id     BIGINT,
+    tag        VARCHAR(50),
+    type       VARCHAR(20) NOT NULL,
+    threshold  BIGINT,
+    percent    DECIMAL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_job_id ON alert_rules (job_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tag ON alert_rules (tag);
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id                    BIGSERIAL PRIMARY KEY,
+    rule_id               BIGINT NOT NULL,
+    observation_id        BIGINT NOT NULL,
+    job_id                BIGINT NOT NULL,
+    type                  VARCHAR(20) NOT NULL,
+    previous_amount       BIGINT,
+    amount                BIGINT,
+    currency              CHAR(3),
+    previous_availability VARCHAR(20),
+    availability          VARCHAR(20),
+    triggered_at          TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_events_rule_observation ON alert_events (rule_id, observation_id);
+CREATE INDEX IF NOT EXISTS idx_alert_events_job_id ON alert_events (job_id);
+CREATE INDEX IF NOT EXISTS idx_alert_events_triggered_at ON alert_events (triggered_at);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id             BIGSERIAL PRIMARY KEY,
+    alert_event_id BIGINT NOT NULL,
+    channel        VARCHAR(50) NOT NULL,
+    channel_type   VARCHAR(20) NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    attempts       BIGINT NOT NULL,
+    last_error     TEXT,
+    created_at     TIMESTAMPTZ,
+    delivered_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_alert_event_id ON notification_deliveries (alert_event_id);
+
+CREATE TABLE IF NOT EXISTS interval_changes (
+    id           BIGSERIAL PRIMARY KEY,
+    job_id       BIGINT NOT NULL,
+    old_interval BIGINT NOT NULL,
+    new_interval BIGINT NOT NULL,
+    reason       VARCHAR(20) NOT NULL,
+    changed_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_interval_changes_job_id ON interval_changes (job_id);
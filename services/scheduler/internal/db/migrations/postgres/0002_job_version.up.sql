@@ -0,0 +1,2 @@
+-- Version for optimistic concurrency control on jobs.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
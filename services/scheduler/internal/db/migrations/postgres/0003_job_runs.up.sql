@@ -0,0 +1,15 @@
+-- History of job executions, one row per dispatch.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id            BIGSERIAL PRIMARY KEY,
+    job_id        BIGINT NOT NULL,
+    dispatched_at TIMESTAMPTZ NOT NULL,
+    started_at    TIMESTAMPTZ,
+    finished_at   TIMESTAMPTZ,
+    outcome       VARCHAR(20),
+    http_status   BIGINT,
+    error         TEXT,
+    worker_id     VARCHAR(100),
+    duration      BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs (job_id);
@@ -0,0 +1,4 @@
+-- Soft delete of jobs, deleted jobs are purged after the retention period.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_deleted_at ON jobs (deleted_at);
@@ -0,0 +1,29 @@
+-- Back to a single price observations table. Archived partitions are not restored.
+DROP TRIGGER IF EXISTS price_observations_rollup ON price_observations;
+DROP FUNCTION IF EXISTS price_daily_rollup();
+DROP TABLE IF EXISTS price_daily;
+DROP FUNCTION IF EXISTS price_observations_ensure_partition(DATE);
+
+ALTER TABLE price_observations RENAME TO price_observations_partitioned;
+ALTER INDEX idx_price_observations_job_observed RENAME TO idx_price_observations_partitioned_job_observed;
+
+CREATE TABLE price_observations (
+    id           BIGINT NOT NULL DEFAULT nextval('price_observations_id_seq') PRIMARY KEY,
+    job_id       BIGINT NOT NULL,
+    observed_at  TIMESTAMPTZ NOT NULL,
+    amount       BIGINT NOT NULL,
+    currency     CHAR(3) NOT NULL,
+    availability VARCHAR(20) NOT NULL,
+    snippet_hash VARCHAR(128),
+    created_at   TIMESTAMPTZ,
+    CONSTRAINT chk_price_observations_amount CHECK (amount >= 0)
+);
+
+ALTER SEQUENCE price_observations_id_seq OWNED BY price_observations.id;
+
+INSERT INTO price_observations (id, job_id, observed_at, amount, currency, availability, snippet_hash, created_at)
+SELECT id, job_id, observed_at, amount, currency, availability, snippet_hash, created_at FROM price_observations_partitioned;
+
+DROP TABLE price_observations_partitioned;
+
+CREATE INDEX idx_price_observations_job_observed ON price_observations (job_id, observed_at);
@@ -0,0 +1,112 @@
+-- Partition price observations by month of observed_at. Observations outside
+-- of all monthly partitions are kept in the default partition until their
+-- month's partition is created.
+
+ALTER TABLE price_observations RENAME TO price_observations_legacy;
+ALTER TABLE price_observations_legacy RENAME CONSTRAINT price_observations_pkey TO price_observations_legacy_pkey;
+DROP INDEX IF EXISTS idx_price_observations_job_observed;
+
+CREATE TABLE price_observations (
+    id           BIGINT NOT NULL DEFAULT nextval('price_observations_id_seq'),
+    job_id       BIGINT NOT NULL,
+    observed_at  TIMESTAMPTZ NOT NULL,
+    amount       BIGINT NOT NULL,
+    currency     CHAR(3) NOT NULL,
+    availability VARCHAR(20) NOT NULL,
+    snippet_hash VARCHAR(128),
+    created_at   TIMESTAMPTZ,
+    CONSTRAINT chk_price_observations_amount CHECK (amount >= 0),
+    -- the partition key must be part of the primary key
+    PRIMARY KEY (id, observed_at)
+) PARTITION BY RANGE (observed_at);
+
+ALTER SEQUENCE price_observations_id_seq OWNED BY price_observations.id;
+
+CREATE INDEX idx_price_observations_job_observed ON price_observations (job_id, observed_at);
+
+CREATE TABLE price_observations_default PARTITION OF price_observations DEFAULT;
+
+-- price_observations_ensure_partition creates the partition of the month of
+-- month_start, named price_observations_yYYYYmMM, and moves its observations
+-- out of the default partition. Returns the name of the partition.
+CREATE OR REPLACE FUNCTION price_observations_ensure_partition(month_start DATE) RETURNS TEXT AS $$
+DECLARE
+    from_ts TIMESTAMPTZ := date_trunc('month', month_start::timestamp) AT TIME ZONE 'UTC';
+    to_ts   TIMESTAMPTZ := (date_trunc('month', month_start::timestamp) + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    name    TEXT := 'price_observations_' || to_char(month_start, '"y"YYYY"m"MM');
+BEGIN
+    -- serialize concurrent callers, e.g. multiple replicas starting at once
+    PERFORM pg_advisory_xact_lock(hashtext('price_observations_partitions'));
+
+    IF to_regclass(name) IS NOT NULL THEN
+        RETURN name;
+    END IF;
+
+    EXECUTE format('CREATE TABLE %I (LIKE price_observations INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', name);
+    EXECUTE format(
+        'WITH moved AS (DELETE FROM price_observations_default WHERE observed_at >= %L AND observed_at < %L RETURNING *)
+         INSERT INTO %I SELECT * FROM moved',
+        from_ts, to_ts, name);
+    EXECUTE format('ALTER TABLE price_observations ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', name, from_ts, to_ts);
+    RETURN name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- partitions for all existing observations and the current month
+SELECT price_observations_ensure_partition(month::date)
+FROM generate_series(
+    date_trunc('month', LEAST(COALESCE((SELECT MIN(observed_at) FROM price_observations_legacy), now()), now()) AT TIME ZONE 'UTC'),
+    date_trunc('month', now() AT TIME ZONE 'UTC'),
+    INTERVAL '1 month'
+) AS month;
+
+INSERT INTO price_observations (id, job_id, observed_at, amount, currency, availability, snippet_hash, created_at)
+SELECT id, job_id, observed_at, amount, currency, availability, snippet_hash, created_at FROM price_observations_legacy;
+DROP TABLE price_observations_legacy;
+
+-- Continuous per-day rollups of the observations of a job, kept up to date on
+-- every insert. Rollups are kept when old partitions are dropped.
+CREATE TABLE price_daily (
+    job_id           BIGINT NOT NULL,
+    day              DATE NOT NULL,
+    min              BIGINT NOT NULL,
+    max              BIGINT NOT NULL,
+    last             BIGINT NOT NULL,
+    currency         CHAR(3) NOT NULL,
+    count            BIGINT NOT NULL,
+    last_observed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (job_id, day)
+);
+
+CREATE OR REPLACE FUNCTION price_daily_rollup() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO price_daily AS d (job_id, day, min, max, last, currency, count, last_observed_at)
+    VALUES (NEW.job_id, (NEW.observed_at AT TIME ZONE 'UTC')::date, NEW.amount, NEW.amount, NEW.amount, NEW.currency, 1, NEW.observed_at)
+    ON CONFLICT (job_id, day) DO UPDATE SET
+        min              = LEAST(d.min, EXCLUDED.min),
+        max              = GREATEST(d.max, EXCLUDED.max),
+        last             = CASE WHEN EXCLUDED.last_observed_at >= d.last_observed_at THEN EXCLUDED.last ELSE d.last END,
+        currency         = CASE WHEN EXCLUDED.last_observed_at >= d.last_observed_at THEN EXCLUDED.currency ELSE d.currency END,
+        last_observed_at = GREATEST(d.last_observed_at, EXCLUDED.last_observed_at),
+        count            = d.count + 1;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER price_observations_rollup
+    AFTER INSERT ON price_observations
+    FOR EACH ROW EXECUTE FUNCTION price_daily_rollup();
+
+-- backfill, the trigger only covers new observations
+INSERT INTO price_daily (job_id, day, min, max, last, currency, count, last_observed_at)
+SELECT job_id,
+       (observed_at AT TIME ZONE 'UTC')::date AS day,
+       MIN(amount),
+       MAX(amount),
+       (array_agg(amount ORDER BY observed_at DESC, id DESC))[1],
+       (array_agg(currency ORDER BY observed_at DESC, id DESC))[1],
+       COUNT(*),
+       MAX(observed_at)
+FROM price_observations
+GROUP BY job_id, day
+ON CONFLICT (job_id, day) DO NOTHING;
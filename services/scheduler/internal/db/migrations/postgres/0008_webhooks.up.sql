@@ -0,0 +1,28 @@
+-- Webhook subscriptions pushed the events of the job lifecycle, and the log of their deliveries.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   BIGSERIAL PRIMARY KEY,
+    url                  VARCHAR(2048) NOT NULL,
+    secret               VARCHAR(200) NOT NULL,
+    event_types          JSONB NOT NULL DEFAULT '[]',
+    enabled              BOOLEAN NOT NULL,
+    consecutive_failures BIGINT NOT NULL DEFAULT 0,
+    disabled_at          TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ,
+    updated_at           TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    event_id        BIGINT NOT NULL,
+    event_type      VARCHAR(30) NOT NULL,
+    job_id          BIGINT NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    attempts        BIGINT NOT NULL,
+    response_code   BIGINT,
+    last_error      TEXT,
+    created_at      TIMESTAMPTZ,
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, created_at);
@@ -0,0 +1,12 @@
+-- API keys of machine clients, only the SHA-256 hash of a key is stored.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    prefix     VARCHAR(20) NOT NULL,
+    key_hash   VARCHAR(64) NOT NULL,
+    role       VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
@@ -0,0 +1,13 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS idx_alert_rules_tenant_id;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS tenant_id;
+
+-- fails if several tenants track the same URL
+DROP INDEX IF EXISTS idx_jobs_tenant_url;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_url ON jobs (url);
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS tenant_id;
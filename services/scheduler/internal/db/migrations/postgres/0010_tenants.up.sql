@@ -0,0 +1,14 @@
+-- Tenants isolate the jobs of the teams sharing the scheduler. Existing rows belong to the default tenant.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- URLs are unique per tenant, so several tenants can track the same product.
+DROP INDEX IF EXISTS idx_jobs_url;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_tenant_url ON jobs (tenant_id, url);
+
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant_id ON alert_rules (tenant_id);
+
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant_id ON webhook_subscriptions (tenant_id);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
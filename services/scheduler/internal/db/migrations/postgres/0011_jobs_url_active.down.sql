@@ -0,0 +1,3 @@
+-- fails if a deleted job has the URL of another job of its tenant
+DROP INDEX IF EXISTS idx_jobs_tenant_url;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_tenant_url ON jobs (tenant_id, url);
@@ -0,0 +1,4 @@
+-- URLs are unique among the jobs that are not deleted, so the URL of a soft deleted job
+-- can be used by a new job while the deleted job can still be restored.
+DROP INDEX IF EXISTS idx_jobs_tenant_url;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_tenant_url ON jobs (tenant_id, url) WHERE deleted_at IS NULL;
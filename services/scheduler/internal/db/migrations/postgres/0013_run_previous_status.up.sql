@@ -0,0 +1,2 @@
+-- Status of the job before a manual run, restored when the run finishes.
+ALTER TABLE job_runs ADD COLUMN IF NOT EXISTS previous_status VARCHAR(20);
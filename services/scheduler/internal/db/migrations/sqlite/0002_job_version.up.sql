@@ -0,0 +1,2 @@
+-- Version for optimistic concurrency control on jobs.
+ALTER TABLE jobs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
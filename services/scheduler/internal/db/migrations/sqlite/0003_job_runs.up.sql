@@ -0,0 +1,15 @@
+-- History of job executions, one row per dispatch.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id        INTEGER NOT NULL,
+    dispatched_at DATETIME NOT NULL,
+    started_at    DATETIME,
+    finished_at   DATETIME,
+    outcome       VARCHAR(20),
+    http_status   INTEGER,
+    error         TEXT,
+    worker_id     VARCHAR(100),
+    duration      INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs (job_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_jobs_deleted_at;
+
+ALTER TABLE jobs DROP COLUMN deleted_at;
@@ -0,0 +1 @@
+ALTER TABLE job_runs DROP COLUMN triggered_by;
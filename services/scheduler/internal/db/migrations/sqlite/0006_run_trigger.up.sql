@@ -0,0 +1,2 @@
+-- What caused a run, ad hoc runs do not affect the schedule of their job.
+ALTER TABLE job_runs ADD COLUMN triggered_by VARCHAR(20) NOT NULL DEFAULT 'schedule';
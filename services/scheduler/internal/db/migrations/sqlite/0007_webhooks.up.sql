@@ -0,0 +1,28 @@
+-- Webhook subscriptions pushed the events of the job lifecycle, and the log of their deliveries.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    url                  VARCHAR(2048) NOT NULL,
+    secret               VARCHAR(200) NOT NULL,
+    event_types          TEXT NOT NULL DEFAULT '[]',
+    enabled              NUMERIC NOT NULL,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at          DATETIME,
+    created_at           DATETIME,
+    updated_at           DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    event_id        INTEGER NOT NULL,
+    event_type      VARCHAR(30) NOT NULL,
+    job_id          INTEGER NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    attempts        INTEGER NOT NULL,
+    response_code   INTEGER,
+    last_error      TEXT,
+    created_at      DATETIME,
+    delivered_at    DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id, created_at);
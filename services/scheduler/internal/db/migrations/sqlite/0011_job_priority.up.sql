@@ -0,0 +1,2 @@
+-- Jobs with a higher priority are dispatched first when more jobs are due than fit into a batch.
+ALTER TABLE jobs ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN -10 AND 10);
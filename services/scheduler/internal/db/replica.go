@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+)
+
+// replicaRetryAfter is how long a failed replica is skipped before it is tried again.
+const replicaRetryAfter = 30 * time.Second
+
+// replica is a read replica and until when it is skipped after a failure.
+type replica struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (r *replica) available(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !now.Before(r.downUntil)
+}
+
+func (r *replica) markDown(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.downUntil = now.Add(replicaRetryAfter)
+}
+
+// routingPool is the connection pool of gorm if read replicas are configured.
+// SELECT queries made with a context allowed by repository.WithReplicaReads are
+// sent to the replicas in turn, all other statements and transactions use the primary.
+// If a replica fails, the query is retried on the primary and the replica is
+// skipped for replicaRetryAfter.
+type routingPool struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+func newRoutingPool(primary *sql.DB, replicas []*sql.DB) *routingPool {
+	pool := &routingPool{primary: primary}
+	for _, db := range replicas {
+		pool.replicas = append(pool.replicas, &replica{db: db})
+	}
+	return pool
+}
+
+func (p *routingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.primary.PrepareContext(ctx, query)
+}
+
+func (p *routingPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.primary.ExecContext(ctx, query, args...)
+}
+
+func (p *routingPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if r := p.replicaFor(ctx, query); r != nil {
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err == nil || ctx.Err() != nil {
+			return rows, err
+		}
+		log.Printf("[WARN] read replica failed, falling back to primary: %v\n", err)
+		r.markDown(time.Now())
+	}
+	return p.primary.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext always uses the primary, as errors of a row are only
+// returned on Scan, too late to fall back.
+func (p *routingPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (p *routingPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return p.primary.BeginTx(ctx, opts)
+}
+
+// GetDBConn returns the primary, e.g. for migrations and connection settings.
+func (p *routingPool) GetDBConn() (*sql.DB, error) {
+	return p.primary, nil
+}
+
+// replicaFor returns the next available replica if the query may be served by one, otherwise nil.
+func (p *routingPool) replicaFor(ctx context.Context, query string) *replica {
+	if len(p.replicas) == 0 || !repository.ReplicaReadsAllowed(ctx) || !isSelect(query) {
+		return nil
+	}
+
+	now := time.Now()
+	start := p.next.Add(1)
+	for i := range p.replicas {
+		r := p.replicas[(start+uint64(i))%uint64(len(p.replicas))]
+		if r.available(now) {
+			return r
+		}
+	}
+	return nil
+}
+
+func isSelect(query string) bool {
+	query = strings.TrimSpace(query)
+	return len(query) >= 6 && strings.EqualFold(query[:6], "SELECT")
+}
@@ -1,6 +1,7 @@
 package dispatcher
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
@@ -18,9 +19,9 @@ func NewLogDispatcher() *logDispatcher {
 	return &logDispatcher{}
 }
 
-func (d *logDispatcher) DispatchJobs(jobs []model.JobDispatched) error {
+func (d *logDispatcher) DispatchJobs(ctx context.Context, jobs []model.JobDispatched) error {
 	for _, job := range jobs {
-		fmt.Printf("dispatching job: id=%d, url=%s\n", uint64(job.ID), job.URL)
+		fmt.Printf("dispatching job: id=%d, run=%d, url=%s\n", uint64(job.ID), uint64(job.RunID), job.URL)
 	}
 	return nil
 }
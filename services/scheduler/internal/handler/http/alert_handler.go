@@ -0,0 +1,151 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type AlertHandler struct {
+	Svc *service.AlertService
+}
+
+func NewAlertHandler(svc *service.AlertService) *AlertHandler {
+	return &AlertHandler{
+		Svc: svc,
+	}
+}
+
+func (h *AlertHandler) CreateRule(c *gin.Context) {
+	var req model.CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	ruleResp, err := h.Svc.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ruleResp)
+}
+
+func (h *AlertHandler) GetRule(c *gin.Context) {
+	id, err := parseRuleID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	ruleResp, err := h.Svc.GetRule(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ruleResp)
+}
+
+func (h *AlertHandler) ListRules(c *gin.Context) {
+	var filter model.ListAlertRulesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedRules, err := h.Svc.ListRules(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedRules)
+}
+
+func (h *AlertHandler) DeleteRule(c *gin.Context) {
+	id, err := parseRuleID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Svc.DeleteRule(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlerts returns the triggered alert events, newest first.
+// Alerts can be filtered by job, rule, type and time range.
+func (h *AlertHandler) ListAlerts(c *gin.Context) {
+	var filter model.ListAlertsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedAlerts, err := h.Svc.ListAlerts(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedAlerts)
+}
+
+// ListDeliveries returns the notification delivery log of an alert.
+func (h *AlertHandler) ListDeliveries(c *gin.Context) {
+	id, err := parseAlertID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListDeliveriesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedDeliveries, err := h.Svc.ListDeliveries(c.Request.Context(), id, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedDeliveries)
+}
+
+func parseRuleID(c *gin.Context) (int, error) {
+	id := c.Param("id")
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, &service.AppError{
+			Message: fmt.Sprintf("invalid alert rule id: %s", id),
+			Code:    "INVALID_ALERT_RULE_ID",
+			Status:  400,
+		}
+	}
+	return idNum, nil
+}
+
+func parseAlertID(c *gin.Context) (int, error) {
+	id := c.Param("id")
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, &service.AppError{
+			Message: fmt.Sprintf("invalid alert id: %s", id),
+			Code:    "INVALID_ALERT_ID",
+			Status:  400,
+		}
+	}
+	return idNum, nil
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type APIKeyHandler struct {
+	Svc *service.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		Svc: svc,
+	}
+}
+
+// CreateKey issues a new API key. The key is only contained in this response.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	keyResp, err := h.Svc.CreateKey(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, keyResp)
+}
+
+func (h *APIKeyHandler) GetKey(c *gin.Context) {
+	id, err := parseAPIKeyID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	keyResp, err := h.Svc.GetKey(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keyResp)
+}
+
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	var filter model.ListAPIKeysFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedKeys, err := h.Svc.ListKeys(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedKeys)
+}
+
+// RevokeKey revokes an API key, the key remains listed with the time it was revoked.
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	id, err := parseAPIKeyID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Svc.RevokeKey(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseAPIKeyID(c *gin.Context) (int, error) {
+	id := c.Param("id")
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, &service.AppError{
+			Message: fmt.Sprintf("invalid api key id: %s", id),
+			Code:    "INVALID_API_KEY_ID",
+			Status:  400,
+		}
+	}
+	return idNum, nil
+}
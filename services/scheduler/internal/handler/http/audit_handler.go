@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type AuditHandler struct {
+	Svc *service.AuditService
+}
+
+func NewAuditHandler(svc *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		Svc: svc,
+	}
+}
+
+// ListEntries returns the audit log as a pagination, newest first.
+// Entries can be filtered by job, actor, action and time range.
+func (h *AuditHandler) ListEntries(c *gin.Context) {
+	var filter model.ListAuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedEntries, err := h.Svc.ListEntries(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedEntries)
+}
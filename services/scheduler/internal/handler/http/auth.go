@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+const (
+	apiKeyHeader        = "X-API-Key"
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	// principalKey is the gin context key of the authenticated principal
+	principalKey = "principal"
+)
+
+// APIKeyAuthenticator resolves API keys to the principal they were issued for.
+type APIKeyAuthenticator interface {
+	// Authenticate returns service.ErrUnauthorized if the key is unknown or revoked.
+	Authenticate(ctx context.Context, key string) (*model.Principal, error)
+}
+
+// TokenAuthenticator resolves bearer tokens to the principal they were issued for.
+type TokenAuthenticator interface {
+	// Authenticate returns service.ErrUnauthorized if the token is invalid,
+	// and service.ErrForbidden if it grants no role.
+	Authenticate(ctx context.Context, token string) (*model.Principal, error)
+}
+
+// Authenticate is a middleware that authenticates requests by the API key of the X-API-Key header,
+// or the bearer token of the Authorization header if tokens is not nil, and rejects requests
+// without valid credentials. The principal is kept in the request context, its subject is
+// recorded as the actor of changes.
+// If enabled is false, every request is made by an anonymous admin of the default tenant.
+func Authenticate(enabled bool, keys APIKeyAuthenticator, tokens TokenAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.Set(principalKey, &model.Principal{Role: model.RoleAdmin, Tenant: model.DefaultTenant})
+			c.Next()
+			return
+		}
+
+		var principal *model.Principal
+		var err error
+		key := strings.TrimSpace(c.GetHeader(apiKeyHeader))
+		token, isBearer := bearerToken(c)
+		switch {
+		case key != "":
+			principal, err = keys.Authenticate(c.Request.Context(), key)
+		case isBearer && tokens != nil:
+			principal, err = tokens.Authenticate(c.Request.Context(), token)
+		default:
+			err = service.ErrUnauthorized
+		}
+		if err == service.ErrUnauthorized && isBearer {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// bearerToken returns the token of an Authorization header with the Bearer scheme.
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader(authorizationHeader)
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):]), true
+}
+
+// RequireRole is a middleware that rejects requests of principals with none of the roles.
+// Admins are allowed every request.
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := principalFromRequest(c)
+		if principal == nil {
+			c.Error(service.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		if !principal.Allows(roles...) {
+			c.Error(service.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScopeTenant is a middleware that restricts the queries of a request to the jobs of the tenant of
+// the principal, and the alert rules, alerts, audit entries, webhooks and API keys belonging to them.
+// Workers only report the results of the jobs of their tenant.
+// The queries of routes without it fail with repository.ErrNoTenant. It must follow Authenticate.
+func ScopeTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := principalFromRequest(c)
+		if principal == nil {
+			c.Error(service.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(repository.WithTenant(c.Request.Context(), principal.Tenant))
+		c.Next()
+	}
+}
+
+// principalFromRequest returns the principal set by Authenticate, nil if there is none.
+func principalFromRequest(c *gin.Context) *model.Principal {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := v.(*model.Principal)
+	return principal
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/inmem"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// credentials resolves API keys and bearer tokens to principals, unknown ones are unauthorized.
+type credentials map[string]*model.Principal
+
+func (c credentials) Authenticate(_ context.Context, secret string) (*model.Principal, error) {
+	principal, ok := c[secret]
+	if !ok {
+		return nil, service.ErrUnauthorized
+	}
+	if principal.Role == "" {
+		return nil, service.ErrForbidden
+	}
+	return principal, nil
+}
+
+var (
+	keys = credentials{
+		"admin-key":    {Subject: "apikey:admin", Role: model.RoleAdmin, Tenant: "team-a"},
+		"reader-key":   {Subject: "apikey:reader", Role: model.RoleReadOnly, Tenant: "team-a"},
+		"operator-key": {Subject: "apikey:operator", Role: model.RoleOperator, Tenant: "team-a"},
+		"worker-key":   {Subject: "apikey:worker", Role: model.RoleWorker, Tenant: "team-a"},
+	}
+	tokens = credentials{
+		"operator-token": {Subject: "alice", Role: model.RoleOperator, Tenant: "team-b"},
+		"no-role-token":  {Subject: "bob"},
+	}
+)
+
+// newAuthRouter returns a router responding to GET /principal with the principal and tenant of the request,
+// after running the middlewares.
+func newAuthRouter(middlewares ...gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(ErrorHandler())
+	handlers := append(middlewares, func(c *gin.Context) {
+		tenant, scoped := repository.TenantFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{
+			"subject": principalFromRequest(c).Subject,
+			"role":    principalFromRequest(c).Role,
+			"tenant":  principalFromRequest(c).Tenant,
+			"scope":   tenant,
+			"scoped":  scoped,
+		})
+	})
+	r.GET("/principal", handlers...)
+	return r
+}
+
+func serve(r http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := map[string]struct {
+		enabled   bool
+		tokens    TokenAuthenticator
+		headers   map[string]string
+		status    int
+		subject   string
+		challenge bool
+		tenant    string
+		role      model.Role
+	}{
+		"disabled": {
+			enabled: false, status: http.StatusOK, role: model.RoleAdmin, tenant: model.DefaultTenant,
+		},
+		"missing credentials": {
+			enabled: true, tokens: tokens, status: http.StatusUnauthorized,
+		},
+		"unknown api key": {
+			enabled: true, tokens: tokens, headers: map[string]string{apiKeyHeader: "guess"}, status: http.StatusUnauthorized,
+		},
+		"api key": {
+			enabled: true, tokens: tokens, headers: map[string]string{apiKeyHeader: "operator-key"},
+			status: http.StatusOK, subject: "apikey:operator", role: model.RoleOperator, tenant: "team-a",
+		},
+		"api key takes precedence over token": {
+			enabled: true, tokens: tokens, headers: map[string]string{apiKeyHeader: "reader-key", authorizationHeader: "Bearer operator-token"},
+			status: http.StatusOK, subject: "apikey:reader", role: model.RoleReadOnly, tenant: "team-a",
+		},
+		"bearer token": {
+			enabled: true, tokens: tokens, headers: map[string]string{authorizationHeader: "bearer operator-token"},
+			status: http.StatusOK, subject: "alice", role: model.RoleOperator, tenant: "team-b",
+		},
+		"invalid bearer token": {
+			enabled: true, tokens: tokens, headers: map[string]string{authorizationHeader: "Bearer forged"},
+			status: http.StatusUnauthorized, challenge: true,
+		},
+		"bearer token without oidc": {
+			enabled: true, headers: map[string]string{authorizationHeader: "Bearer operator-token"},
+			status: http.StatusUnauthorized, challenge: true,
+		},
+		"token without role": {
+			enabled: true, tokens: tokens, headers: map[string]string{authorizationHeader: "Bearer no-role-token"},
+			status: http.StatusForbidden,
+		},
+		"other scheme": {
+			enabled: true, tokens: tokens, headers: map[string]string{authorizationHeader: "Basic b3BlcmF0b3I6c2VjcmV0"},
+			status: http.StatusUnauthorized,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newAuthRouter(Authenticate(tt.enabled, keys, tt.tokens))
+			req := httptest.NewRequest(http.MethodGet, "/principal", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			w := serve(r, req)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Equal(t, tt.challenge, w.Header().Get("WWW-Authenticate") != "")
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Subject string
+				Role    model.Role
+				Tenant  string
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.subject, body.Subject)
+			assert.Equal(t, tt.role, body.Role)
+			assert.Equal(t, tt.tenant, body.Tenant)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := map[string]struct {
+		key    string
+		roles  []model.Role
+		status int
+	}{
+		"role allowed":        {key: "reader-key", roles: []model.Role{model.RoleReadOnly, model.RoleOperator}, status: http.StatusOK},
+		"role not allowed":    {key: "reader-key", roles: []model.Role{model.RoleOperator}, status: http.StatusForbidden},
+		"worker not allowed":  {key: "worker-key", roles: []model.Role{model.RoleReadOnly, model.RoleOperator}, status: http.StatusForbidden},
+		"worker allowed":      {key: "worker-key", roles: []model.Role{model.RoleWorker}, status: http.StatusOK},
+		"admin always allows": {key: "admin-key", roles: []model.Role{model.RoleWorker}, status: http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newAuthRouter(Authenticate(true, keys, nil), RequireRole(tt.roles...))
+			req := httptest.NewRequest(http.MethodGet, "/principal", nil)
+			req.Header.Set(apiKeyHeader, tt.key)
+
+			w := serve(r, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("without principal", func(t *testing.T) {
+		r := newAuthRouter(RequireRole(model.RoleReadOnly))
+		w := serve(r, httptest.NewRequest(http.MethodGet, "/principal", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestScopeTenant(t *testing.T) {
+	r := newAuthRouter(Authenticate(true, keys, tokens), ScopeTenant())
+	for header, want := range map[string]string{apiKeyHeader: "team-a", authorizationHeader: "team-b"} {
+		req := httptest.NewRequest(http.MethodGet, "/principal", nil)
+		if header == apiKeyHeader {
+			req.Header.Set(header, "operator-key")
+		} else {
+			req.Header.Set(header, "Bearer operator-token")
+		}
+
+		w := serve(r, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body struct {
+			Scope  string
+			Scoped bool
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Scoped)
+		assert.Equal(t, want, body.Scope)
+	}
+
+	t.Run("without principal", func(t *testing.T) {
+		r := newAuthRouter(ScopeTenant())
+		w := serve(r, httptest.NewRequest(http.MethodGet, "/principal", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// tenantRecorder records the tenants the job lookups are scoped to.
+type tenantRecorder struct {
+	service.JobRepository
+	tenants []string
+}
+
+func (r *tenantRecorder) GetByID(ctx context.Context, id int) (*model.Job, error) {
+	tenant, _ := repository.TenantFromContext(ctx)
+	r.tenants = append(r.tenants, tenant)
+	return r.JobRepository.GetByID(ctx, id)
+}
+
+func TestWorkerResultsAreScopedToTenant(t *testing.T) {
+	validator.RegisterValidators()
+
+	jobs := inmem.New()
+	job := &model.Job{URL: "https://shop.test/b", Interval: time.Hour, Status: model.JobStatusInProgress, TenantID: "team-b", Tags: model.Tags{}}
+	require.NoError(t, jobs.Save(t.Context(), job))
+	recorder := &tenantRecorder{JobRepository: jobs}
+
+	resultSvc := service.NewResultService(recorder, nil, nil, nil, nil, nil, nil, 3)
+	r := SetupRouter(time.Minute, Authenticate(true, keys, nil), nil, nil, nil, nil, NewResultHandler(resultSvc), nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/1/results", strings.NewReader(`{"outcome":"failure","error":"timeout"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiKeyHeader, "worker-key")
+
+	w := serve(r, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "the job of another tenant must not be found: %s", w.Body.String())
+	assert.Equal(t, []string{"team-a"}, recorder.tenants)
+}
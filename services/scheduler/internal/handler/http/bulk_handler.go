@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type BulkHandler struct {
+	Svc *service.BulkService
+}
+
+func NewBulkHandler(svc *service.BulkService) *BulkHandler {
+	return &BulkHandler{
+		Svc: svc,
+	}
+}
+
+// BulkJobs starts an operation applying an action to the selected jobs.
+// The progress of the operation is available at the returned Location.
+func (h *BulkHandler) BulkJobs(c *gin.Context) {
+	var req model.BulkJobsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	opResp, err := h.Svc.StartOperation(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/operations/"+opResp.ID)
+	c.JSON(http.StatusAccepted, opResp)
+}
+
+// GetOperation returns the progress of a bulk operation.
+func (h *BulkHandler) GetOperation(c *gin.Context) {
+	opResp, err := h.Svc.GetOperation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, opResp)
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"reflect"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -25,6 +27,14 @@ var ErrInternalServer = APIError{
 	Code:    "INTERNAL_SERVER_ERROR",
 }
 
+var ErrTimeout = APIError{
+	Message: "request timed out",
+	Code:    "TIMEOUT",
+}
+
+// statusClientClosedRequest is returned if the client aborted the request, nobody reads the response.
+const statusClientClosedRequest = 499
+
 // ErrorHandler is a middleware that handles service-level errors and validation errors
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -49,6 +59,16 @@ func ErrorHandler() gin.HandlerFunc {
 			return
 		}
 
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, &ErrTimeout)
+			return
+		}
+
+		if errors.Is(err, context.Canceled) {
+			c.AbortWithStatus(statusClientClosedRequest)
+			return
+		}
+
 		c.AbortWithStatusJSON(http.StatusInternalServerError, &ErrInternalServer)
 	}
 }
@@ -95,8 +115,14 @@ func validationErrorMsg(fe validator.FieldError) string {
 	case "email":
 		return "must be a valid email"
 	case "min":
+		if isNumber(fe.Kind()) {
+			return "must be at least " + fe.Param()
+		}
 		return "must be at least " + fe.Param() + " characters long"
 	case "max":
+		if isNumber(fe.Kind()) {
+			return "must be at most " + fe.Param()
+		}
 		return "must be at most " + fe.Param() + " characters long"
 	case "interval":
 		return "interval must be a valid duration (e.g., '10s', '5m', '1h') and at least 1 hour"
@@ -106,7 +132,44 @@ func validationErrorMsg(fe validator.FieldError) string {
 		return "must be one of: asc, desc"
 	case "jobsortcol":
 		return "must be one of: created_at, next_run_at, url, status"
+	case "runoutcome":
+		return "must be one of: success, not_modified, failure"
+	case "availability":
+		return "must be one of: in_stock, out_of_stock, unknown"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "required_if", "required_without":
+		return "field is required"
+	case "excluded_with":
+		return "must not be set together with " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "alertruletype":
+		return "must be one of: price_below, price_change, back_in_stock, out_of_stock"
+	case "auditaction":
+		return "must be one of: create, update, pause, resume, delete, restore, status_change"
+	case "bulkaction":
+		return "must be one of: pause, resume, delete"
+	case "eventtype":
+		return "must be one of: job.created, job.dispatched, job.completed, job.failed, job.paused, price.changed"
+	case "role":
+		return "must be one of: admin, operator, read_only, worker"
+	case "tenant":
+		return "must be up to 64 lowercase letters, digits, dots, underscores or dashes"
+	case "oneof":
+		return "must be one of: " + fe.Param()
 	default:
 		return fe.Error()
 	}
 }
+
+// isNumber reports whether a field of kind k is validated by value rather than by length.
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+
+	// defaultHeartbeat is the interval of heartbeats if none is configured.
+	defaultHeartbeat = 15 * time.Second
+
+	// eventReset tells a client resuming a stream that events were missed,
+	// it has to reload the state of the jobs.
+	eventReset = "reset"
+	// eventHeartbeat keeps idle streams open through proxies.
+	eventHeartbeat = "heartbeat"
+)
+
+type EventHandler struct {
+	Svc       *service.EventService
+	Heartbeat time.Duration
+}
+
+// NewEventHandler instantiates an EventHandler sending a heartbeat on idle streams every heartbeat.
+func NewEventHandler(svc *service.EventService, heartbeat time.Duration) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return &EventHandler{
+		Svc:       svc,
+		Heartbeat: heartbeat,
+	}
+}
+
+// StreamEvents sends the job lifecycle events of the tenant of the caller as Server-Sent Events until
+// the client disconnects, optionally filtered by job ID or tag. Clients reconnecting with the Last-Event-ID
+// header (or the lastEventId query parameter) first receive the buffered events they missed.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	var filter model.EventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+	filter.TenantID = principalFromRequest(c).Tenant
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sub, replay, complete := h.Svc.Subscribe(&filter, lastEventID)
+	defer h.Svc.Unsubscribe(sub)
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !complete {
+		c.Render(-1, sse.Event{Event: eventReset, Data: gin.H{"message": "events were missed, reload the jobs"}})
+	}
+	for _, event := range replay {
+		renderEvent(c, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// the client fell behind, it resumes from the buffer when reconnecting
+				return
+			}
+			renderEvent(c, event)
+		case t := <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: eventHeartbeat, Data: gin.H{"time": t}})
+		}
+		c.Writer.Flush()
+	}
+}
+
+func renderEvent(c *gin.Context, event *model.Event) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(event.ID, 10),
+		Event: string(event.Type),
+		Data:  model.ToEventResponse(event),
+	})
+}
+
+func parseLastEventID(c *gin.Context) (*uint64, error) {
+	value := strings.TrimSpace(c.GetHeader(lastEventIDHeader))
+	if value == "" {
+		value = c.Query("lastEventId")
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, &service.AppError{
+			Message: fmt.Sprintf("invalid last event id: %s", value),
+			Code:    "INVALID_LAST_EVENT_ID",
+			Status:  http.StatusBadRequest,
+		}
+	}
+	return &id, nil
+}
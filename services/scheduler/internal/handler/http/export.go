@@ -0,0 +1,356 @@
+package http
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/parquet"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+// exportFormat is the file format of an export, selected by the query parameter format.
+type exportFormat string
+
+const (
+	exportFormatCSV     exportFormat = "csv"
+	exportFormatNDJSON  exportFormat = "ndjson"
+	exportFormatParquet exportFormat = "parquet"
+)
+
+const (
+	// exportFlushRows is the number of rows after which an export is flushed to the client.
+	exportFlushRows = 100
+
+	// exportRowGroupRows is the number of rows of a row group of a Parquet export,
+	// the rows of a row group are held in memory until it is written.
+	exportRowGroupRows = 10000
+)
+
+// jobExportColumns are the columns of a job export, the values of a row are returned by jobRecord.
+var jobExportColumns = []parquet.Column{
+	{Name: "id", Type: parquet.Int64},
+	{Name: "url", Type: parquet.String},
+	{Name: "status", Type: parquet.String},
+	{Name: "tags", Type: parquet.String},
+	{Name: "interval", Type: parquet.String},
+	{Name: "priority", Type: parquet.Int64},
+	{Name: "adaptive", Type: parquet.Bool},
+	{Name: "minInterval", Type: parquet.String, Optional: true},
+	{Name: "maxInterval", Type: parquet.String, Optional: true},
+	{Name: "retryAttempts", Type: parquet.Int64},
+	{Name: "version", Type: parquet.Int64},
+	{Name: "dispatchedAt", Type: parquet.Timestamp, Optional: true},
+	{Name: "nextRunAt", Type: parquet.Timestamp, Optional: true},
+	{Name: "createdAt", Type: parquet.Timestamp},
+	{Name: "updatedAt", Type: parquet.Timestamp},
+	{Name: "deletedAt", Type: parquet.Timestamp, Optional: true},
+}
+
+// priceExportColumns are the columns of a price export, the values of a row are returned by priceRecord.
+var priceExportColumns = []parquet.Column{
+	{Name: "id", Type: parquet.Int64},
+	{Name: "jobId", Type: parquet.Int64},
+	{Name: "observedAt", Type: parquet.Timestamp},
+	{Name: "amount", Type: parquet.Int64},
+	{Name: "currency", Type: parquet.String},
+	{Name: "availability", Type: parquet.String},
+	{Name: "snippetHash", Type: parquet.String},
+}
+
+// ExportJobs streams all jobs matching the filters of ListJobs as CSV, NDJSON or Parquet.
+// The pagination parameters are ignored.
+func (h *JobHandler) ExportJobs(c *gin.Context) {
+	format, err := parseExportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListJobsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	e := newExport(c, format, "jobs", jobExportColumns)
+	err = h.Svc.ExportJobs(c.Request.Context(), &filter, func(job *model.JobResponse) error {
+		return e.write(job, jobRecord(job))
+	})
+	e.finish(err)
+}
+
+// ExportPrices streams the raw price history of a job as CSV, NDJSON or Parquet.
+// The pagination parameters are ignored.
+func (h *PriceHandler) ExportPrices(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	format, err := parseExportFormat(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListPricesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	e := newExport(c, format, fmt.Sprintf("job-%d-prices", id), priceExportColumns)
+	err = h.Svc.ExportPrices(c.Request.Context(), id, &filter, func(observation *model.PriceObservationResponse) error {
+		return e.write(observation, priceRecord(observation))
+	})
+	e.finish(err)
+}
+
+func parseExportFormat(c *gin.Context) (exportFormat, error) {
+	format := exportFormat(c.DefaultQuery("format", string(exportFormatCSV)))
+	switch format {
+	case exportFormatCSV, exportFormatNDJSON, exportFormatParquet:
+		return format, nil
+	}
+	return "", &service.AppError{
+		Message: fmt.Sprintf("unsupported format: %s, expected csv, ndjson or parquet", format),
+		Code:    "INVALID_FORMAT",
+		Status:  http.StatusBadRequest,
+	}
+}
+
+// export writes the rows of an export to the response, gzip compressed if the client accepts it.
+// The response is started with the first row, so errors before it are still reported as JSON.
+type export struct {
+	c       *gin.Context
+	format  exportFormat
+	name    string
+	columns []parquet.Column
+
+	started bool
+	rows    int
+	gzip    *gzip.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+	parquet *parquet.Writer
+}
+
+func newExport(c *gin.Context, format exportFormat, name string, columns []parquet.Column) *export {
+	return &export{c: c, format: format, name: name, columns: columns}
+}
+
+// start writes the headers of the response and the header row of a CSV file.
+func (e *export) start() error {
+	e.started = true
+
+	contentType := "text/csv; charset=utf-8"
+	switch e.format {
+	case exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	case exportFormatParquet:
+		contentType = "application/vnd.apache.parquet"
+	}
+	e.c.Header("Content-Type", contentType)
+	e.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, e.name, e.format))
+	e.c.Header("Vary", "Accept-Encoding")
+
+	var w io.Writer = e.c.Writer
+	if acceptsGzip(e.c.GetHeader("Accept-Encoding")) {
+		e.c.Header("Content-Encoding", "gzip")
+		e.gzip = gzip.NewWriter(w)
+		w = e.gzip
+	}
+	e.c.Status(http.StatusOK)
+
+	switch e.format {
+	case exportFormatNDJSON:
+		e.json = json.NewEncoder(w)
+		return nil
+	case exportFormatParquet:
+		e.parquet = parquet.NewWriter(w, e.columns, exportRowGroupRows)
+		return nil
+	}
+	e.csv = csv.NewWriter(w)
+	header := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		header[i] = column.Name
+	}
+	return e.csv.Write(header)
+}
+
+// write adds a row, v is written as NDJSON and the values of the columns as CSV or Parquet.
+func (e *export) write(v any, values []any) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch {
+	case e.json != nil:
+		err = e.json.Encode(v)
+	case e.parquet != nil:
+		err = e.parquet.Write(values)
+	default:
+		err = e.csv.Write(csvRecord(values))
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// flush sends the buffered rows to the client.
+func (e *export) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if e.gzip != nil {
+		if err := e.gzip.Flush(); err != nil {
+			return err
+		}
+	}
+	e.c.Writer.Flush()
+	return nil
+}
+
+// finish completes the response. An error before the first row is reported like any other error,
+// afterwards the status was already sent, the response is cut off and the error is only logged.
+func (e *export) finish(err error) {
+	if err == nil && !e.started {
+		err = e.start()
+	}
+	if err != nil {
+		if !e.started {
+			e.c.Error(err)
+			return
+		}
+		log.Printf("[ERROR] export %s aborted after %d rows: %v\n", e.name, e.rows, err)
+		e.c.Abort()
+		return
+	}
+
+	if e.parquet != nil {
+		if err := e.parquet.Close(); err != nil {
+			log.Printf("[ERROR] export %s failed to write the Parquet footer: %v\n", e.name, err)
+			e.c.Abort()
+			return
+		}
+	}
+	if err := e.flush(); err != nil {
+		log.Printf("[ERROR] export %s failed to flush: %v\n", e.name, err)
+		return
+	}
+	if e.gzip != nil {
+		if err := e.gzip.Close(); err != nil {
+			log.Printf("[ERROR] export %s failed to close gzip stream: %v\n", e.name, err)
+		}
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows a gzip encoded response.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		if !strings.HasPrefix(q, "q=") {
+			return true
+		}
+		weight, err := strconv.ParseFloat(strings.TrimPrefix(q, "q="), 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// jobRecord returns the values of the jobExportColumns of a job.
+func jobRecord(job *model.JobResponse) []any {
+	return []any{
+		int64(job.ID),
+		job.URL,
+		string(job.Status),
+		strings.Join(job.Tags, importTagSeparator),
+		job.Interval,
+		int64(job.Priority),
+		job.Adaptive,
+		optionalString(job.MinInterval),
+		optionalString(job.MaxInterval),
+		int64(job.RetryAttempts),
+		job.Version,
+		optionalTime(job.DispatchedAt),
+		optionalTime(job.NextRunAt),
+		job.CreatedAt,
+		job.UpdatedAt,
+		optionalTime(job.DeletedAt),
+	}
+}
+
+// priceRecord returns the values of the priceExportColumns of an observation.
+func priceRecord(observation *model.PriceObservationResponse) []any {
+	return []any{
+		int64(observation.ID),
+		int64(observation.JobID),
+		observation.ObservedAt,
+		observation.Amount,
+		observation.Currency,
+		string(observation.Availability),
+		observation.SnippetHash,
+	}
+}
+
+// csvRecord formats the values of a row as CSV fields, times like JSON and missing values as empty fields.
+func csvRecord(values []any) []string {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339Nano)
+		}
+	}
+	return record
+}
+
+// optionalString returns nil for an empty string, so it is exported as a missing value.
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalTime returns the time t points to, or nil if it is missing.
+func optionalTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
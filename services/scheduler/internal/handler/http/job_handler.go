@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
@@ -30,12 +31,13 @@ func (h *JobHandler) CreateJob(c *gin.Context) {
 		return
 	}
 
-	jobResp, err := h.Svc.CreateJob(&req)
+	jobResp, err := h.Svc.CreateJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), &req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", jobETag(jobResp.Version))
 	c.JSON(201, jobResp)
 }
 
@@ -46,12 +48,18 @@ func (h *JobHandler) GetJob(c *gin.Context) {
 		return
 	}
 
-	jobResp, err := h.Svc.GetJob(id)
+	jobResp, err := h.Svc.GetJob(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	etag := jobETag(jobResp.Version)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(200, jobResp)
 }
 
@@ -64,7 +72,7 @@ func (h *JobHandler) ListJobs(c *gin.Context) {
 		return
 	}
 
-	paginatedJobs, err := h.Svc.ListJobs(&filter)
+	paginatedJobs, err := h.Svc.ListJobs(c.Request.Context(), &filter)
 	if err != nil {
 		c.Error(err)
 		return
@@ -73,6 +81,53 @@ func (h *JobHandler) ListJobs(c *gin.Context) {
 	c.JSON(http.StatusOK, paginatedJobs)
 }
 
+// ListIntervalChanges returns the history of interval changes of a job, newest first.
+func (h *JobHandler) ListIntervalChanges(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListIntervalChangesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedChanges, err := h.Svc.ListIntervalChanges(c.Request.Context(), id, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedChanges)
+}
+
+// ListRuns returns the execution history of a job, newest first.
+// Runs can be filtered by outcome.
+func (h *JobHandler) ListRuns(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListRunsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedRuns, err := h.Svc.ListRuns(c.Request.Context(), id, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedRuns)
+}
+
 func (h *JobHandler) PauseJob(c *gin.Context) {
 	id, err := parseJobID(c)
 	if err != nil {
@@ -80,12 +135,19 @@ func (h *JobHandler) PauseJob(c *gin.Context) {
 		return
 	}
 
-	jobResp, err := h.Svc.PauseJob(id)
+	ifMatch, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	jobResp, err := h.Svc.PauseJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id, ifMatch)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	c.Header("ETag", jobETag(jobResp.Version))
 	c.JSON(200, jobResp)
 }
 
@@ -96,12 +158,77 @@ func (h *JobHandler) ResumeJob(c *gin.Context) {
 		return
 	}
 
-	jobResp, err := h.Svc.ResumeJob(id)
+	ifMatch, err := parseIfMatch(c)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	jobResp, err := h.Svc.ResumeJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id, ifMatch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", jobETag(jobResp.Version))
+	c.JSON(200, jobResp)
+}
+
+// UpdateJob applies a JSON Merge Patch to the mutable fields of a job.
+// The body is accepted as application/merge-patch+json or application/json.
+func (h *JobHandler) UpdateJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.Error(&service.AppError{
+			Message: fmt.Sprintf("unsupported content type: %s", contentType),
+			Code:    "UNSUPPORTED_MEDIA_TYPE",
+			Status:  http.StatusUnsupportedMediaType,
+		})
+		return
+	}
+
+	ifMatch, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.PatchJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	jobResp, err := h.Svc.UpdateJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id, ifMatch, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", jobETag(jobResp.Version))
+	c.JSON(200, jobResp)
+}
+
+// RestoreJob restores a soft deleted job.
+func (h *JobHandler) RestoreJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	jobResp, err := h.Svc.RestoreJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", jobETag(jobResp.Version))
 	c.JSON(200, jobResp)
 }
 
@@ -112,7 +239,13 @@ func (h *JobHandler) DeleteJob(c *gin.Context) {
 		return
 	}
 
-	err = h.Svc.DeleteJob(id)
+	ifMatch, err := parseIfMatch(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = h.Svc.DeleteJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id, ifMatch)
 	if err != nil {
 		c.Error(err)
 		return
@@ -133,3 +266,57 @@ func parseJobID(c *gin.Context) (int, error) {
 	}
 	return idNum, nil
 }
+
+// jobETag returns the entity tag of a job, which is its quoted version.
+func jobETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch returns the precondition of the If-Match header, a comma separated list of
+// entity tags, or nil if the header is missing or "*". If-Match uses the strong comparison,
+// so weak tags (W/"3") are parsed but never match. Tags that are no job version never match either.
+func parseIfMatch(c *gin.Context) (*model.IfMatch, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	invalid := &service.AppError{
+		Message: fmt.Sprintf("invalid If-Match header: %s", header),
+		Code:    "INVALID_IF_MATCH",
+		Status:  400,
+	}
+
+	ifMatch := &model.IfMatch{}
+	for rest := header; rest != ""; {
+		weak := strings.HasPrefix(rest, "W/")
+		if weak {
+			rest = rest[len("W/"):]
+		}
+		// entity tags are quoted and contain no quotes
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, invalid
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, invalid
+		}
+		tag := rest[1 : end+1]
+		if version, err := strconv.ParseInt(tag, 10, 64); err == nil && !weak {
+			ifMatch.Versions = append(ifMatch.Versions, version)
+		}
+
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, invalid
+		}
+		rest = strings.TrimLeft(rest[1:], " \t")
+		if rest == "" {
+			return nil, invalid
+		}
+	}
+	return ifMatch, nil
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := map[string]struct {
+		header  string
+		want    *model.IfMatch
+		invalid bool
+	}{
+		"missing":           {header: "", want: nil},
+		"any":               {header: "*", want: nil},
+		"strong":            {header: `"3"`, want: &model.IfMatch{Versions: []int64{3}}},
+		"list":              {header: `"2", "3"`, want: &model.IfMatch{Versions: []int64{2, 3}}},
+		"list without OWS":  {header: `"2","3"`, want: &model.IfMatch{Versions: []int64{2, 3}}},
+		"weak":              {header: `W/"3"`, want: &model.IfMatch{}},
+		"weak and strong":   {header: `W/"2", "3"`, want: &model.IfMatch{Versions: []int64{3}}},
+		"no version":        {header: `"abc"`, want: &model.IfMatch{}},
+		"unquoted":          {header: "3", invalid: true},
+		"unterminated":      {header: `"3`, invalid: true},
+		"trailing comma":    {header: `"3",`, invalid: true},
+		"missing separator": {header: `"2" "3"`, invalid: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("PATCH", "/api/v1/jobs/1", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			ifMatch, err := parseIfMatch(c)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ifMatch)
+		})
+	}
+}
+
+func TestIfMatchMatches(t *testing.T) {
+	var none *model.IfMatch
+	assert.True(t, none.Matches(3))
+	assert.True(t, (&model.IfMatch{Versions: []int64{2, 3}}).Matches(3))
+	assert.False(t, (&model.IfMatch{Versions: []int64{2}}).Matches(3))
+	// only weak tags, nothing matches
+	assert.False(t, (&model.IfMatch{}).Matches(3))
+}
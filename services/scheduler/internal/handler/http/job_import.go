@@ -0,0 +1,273 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+const (
+	// maxImportRows is the maximum number of rows of an imported file.
+	maxImportRows = 10000
+
+	// maxImportSize is the maximum size of an imported file in bytes.
+	maxImportSize = 16 << 20
+
+	// maxImportLineSize is the maximum size of a single NDJSON line in bytes.
+	maxImportLineSize = 64 << 10
+
+	// importTagSeparator separates the tags in the tags column of a CSV file.
+	importTagSeparator = ";"
+)
+
+// importColumns are the columns of an imported CSV file, url and interval are required.
+var importColumns = map[string]bool{
+	"url":         true,
+	"interval":    true,
+	"tags":        true,
+	"priority":    true,
+	"adaptive":    true,
+	"minInterval": true,
+	"maxInterval": true,
+}
+
+var errTooManyRows = &service.AppError{
+	Message: fmt.Sprintf("the file has more than %d rows", maxImportRows),
+	Code:    "TOO_MANY_ROWS",
+	Status:  http.StatusRequestEntityTooLarge,
+}
+
+// ImportJobs creates jobs from a CSV (text/csv) or NDJSON (application/x-ndjson) file.
+// With the query parameter dryRun=true the rows are only checked.
+func (h *JobHandler) ImportJobs(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		c.Error(&service.AppError{
+			Message: fmt.Sprintf("invalid dryRun: %s", c.Query("dryRun")),
+			Code:    "INVALID_DRY_RUN",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var rows []*model.ImportJobRow
+	switch contentType := c.ContentType(); contentType {
+	case "text/csv":
+		rows, err = parseCSVRows(body)
+	case "application/x-ndjson", "application/ndjson":
+		rows, err = parseNDJSONRows(body)
+	default:
+		err = &service.AppError{
+			Message: fmt.Sprintf("unsupported content type: %s, expected text/csv or application/x-ndjson", contentType),
+			Code:    "UNSUPPORTED_MEDIA_TYPE",
+			Status:  http.StatusUnsupportedMediaType,
+		}
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = &service.AppError{
+			Message: fmt.Sprintf("the file is larger than %d bytes", maxBytesErr.Limit),
+			Code:    "FILE_TOO_LARGE",
+			Status:  http.StatusRequestEntityTooLarge,
+		}
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	for _, row := range rows {
+		if len(row.Errors) == 0 {
+			row.Errors = validateImportRow(row.Job)
+		}
+	}
+
+	resp, err := h.Svc.ImportJobs(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), rows, dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun && resp.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, resp)
+}
+
+// parseCSVRows reads the rows of a CSV file with a header naming the columns.
+// Tags are separated by importTagSeparator. Rows with the wrong number of fields
+// are reported as invalid, other syntax errors abort the import.
+func parseCSVRows(r io.Reader) ([]*model.ImportJobRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, invalidImport("the file is empty, expected a header")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !importColumns[name] {
+			return nil, invalidImport(fmt.Sprintf("unknown column %q, expected url, interval, tags, priority, adaptive, minInterval and maxInterval", name))
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"url", "interval"} {
+		if _, ok := columns[name]; !ok {
+			return nil, invalidImport(fmt.Sprintf("missing column %q", name))
+		}
+	}
+
+	var rows []*model.ImportJobRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, csvError(err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &model.ImportJobRow{Line: line}
+		rows = append(rows, row)
+		if err != nil {
+			row.Errors = []model.ImportError{{Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))}}
+			continue
+		}
+
+		row.Job, row.Errors = csvJob(record, columns)
+	}
+}
+
+// csvJob returns the request of a CSV record.
+func csvJob(record []string, columns map[string]int) (*model.CreateJobRequest, []model.ImportError) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &model.CreateJobRequest{
+		URL:         field("url"),
+		Interval:    field("interval"),
+		MinInterval: field("minInterval"),
+		MaxInterval: field("maxInterval"),
+	}
+
+	if tags := field("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, importTagSeparator) {
+			req.Tags = append(req.Tags, strings.TrimSpace(tag))
+		}
+	}
+
+	if priority := field("priority"); priority != "" {
+		value, err := strconv.Atoi(priority)
+		if err != nil {
+			return req, []model.ImportError{{Field: "priority", Message: "must be an integer"}}
+		}
+		req.Priority = &value
+	}
+
+	if adaptive := field("adaptive"); adaptive != "" {
+		value, err := strconv.ParseBool(adaptive)
+		if err != nil {
+			return req, []model.ImportError{{Field: "adaptive", Message: "must be true or false"}}
+		}
+		req.Adaptive = value
+	}
+	return req, nil
+}
+
+// parseNDJSONRows reads the rows of a file with a JSON object per line, like the body of POST /jobs.
+// Empty lines are skipped, lines that are no valid object are reported as invalid.
+func parseNDJSONRows(r io.Reader) ([]*model.ImportJobRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineSize)
+
+	var rows []*model.ImportJobRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, errTooManyRows
+		}
+
+		row := &model.ImportJobRow{Line: line, Job: &model.CreateJobRequest{}}
+		rows = append(rows, row)
+
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(row.Job); err != nil {
+			row.Errors = []model.ImportError{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+		}
+	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil, invalidImport(fmt.Sprintf("line %d is longer than %d bytes", line+1, maxImportLineSize))
+	}
+	return rows, scanner.Err()
+}
+
+// validateImportRow validates the request of a row like the binding of POST /jobs.
+func validateImportRow(req *model.CreateJobRequest) []model.ImportError {
+	err := binding.Validator.ValidateStruct(req)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []model.ImportError{{Message: err.Error()}}
+	}
+	errs := make([]model.ImportError, 0, len(verrs))
+	for _, fe := range verrs {
+		errs = append(errs, model.ImportError{Field: fe.Field(), Message: validationErrorMsg(fe)})
+	}
+	return errs
+}
+
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return invalidImport(fmt.Sprintf("line %d: %v", parseErr.Line, parseErr.Err))
+	}
+	return err
+}
+
+func invalidImport(msg string) *service.AppError {
+	return &service.AppError{
+		Message: msg,
+		Code:    "INVALID_IMPORT",
+		Status:  http.StatusBadRequest,
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	actorHeader     = "X-Actor"
+
+	// requestIDKey is the gin context key of the request ID
+	requestIDKey = "requestID"
+
+	maxRequestIDLength = 64
+	maxActorLength     = 100
+)
+
+// RequestID is a middleware that assigns every request an ID. The ID of the
+// X-Request-ID header is kept if present, otherwise a random ID is generated.
+// The ID is returned in the X-Request-ID response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(requestIDHeader))
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestTimeout is a middleware that cancels the context of a request after timeout,
+// aborting the database queries made while handling it. Zero disables the timeout.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// actorFromRequest returns who made the request: the authenticated principal,
+// or if authentication is disabled the name of the X-Actor header or defaultName if it is missing.
+func actorFromRequest(c *gin.Context, defaultName string) model.Actor {
+	var name string
+	if principal := principalFromRequest(c); principal != nil && principal.Subject != "" {
+		name = principal.Subject
+	} else {
+		name = strings.TrimSpace(c.GetHeader(actorHeader))
+	}
+	if name == "" {
+		name = defaultName
+	}
+	if len(name) > maxActorLength {
+		name = name[:maxActorLength]
+	}
+	return model.Actor{
+		Name:      name,
+		RequestID: c.GetString(requestIDKey),
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type PriceHandler struct {
+	Svc *service.PriceService
+}
+
+func NewPriceHandler(svc *service.PriceService) *PriceHandler {
+	return &PriceHandler{
+		Svc: svc,
+	}
+}
+
+// ListPrices returns the price history of a job as a pagination.
+// With resolution=day the history is downsampled to min/max/last per day.
+func (h *PriceHandler) ListPrices(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListPricesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if filter.Resolution != nil && *filter.Resolution == model.PriceResolutionDay {
+		paginatedDays, err := h.Svc.ListDailyPrices(c.Request.Context(), id, &filter)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, paginatedDays)
+		return
+	}
+
+	paginatedPrices, err := h.Svc.ListPrices(c.Request.Context(), id, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedPrices)
+}
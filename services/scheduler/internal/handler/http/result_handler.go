@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type ResultHandler struct {
+	Svc *service.ResultService
+}
+
+func NewResultHandler(svc *service.ResultService) *ResultHandler {
+	return &ResultHandler{
+		Svc: svc,
+	}
+}
+
+// ReportResult is called by workers once a dispatched job finished.
+func (h *ResultHandler) ReportResult(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req model.ReportResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	jobResp, err := h.Svc.ReportResult(c.Request.Context(), actorFromRequest(c, model.ActorWorker), id, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobResp)
+}
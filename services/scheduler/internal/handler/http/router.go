@@ -1,27 +1,113 @@
 package http
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
 )
 
-// SetupRouter wires up all routes and returns a *gin.Engine
-func SetupRouter(jobHandler *JobHandler) *gin.Engine {
+// SetupRouter wires up all routes and returns a *gin.Engine.
+// Requests are cancelled after requestTimeout. All API routes are authenticated by authenticate
+// and restricted to the roles of their group: read-only principals can query, operators can also
+// make changes, workers can only report results and admins can do everything, including managing API keys.
+// Queries and changes are scoped to the tenant of the principal, including the API keys admins manage
+// and the results reported by workers, which only run the jobs of their tenant.
+func SetupRouter(requestTimeout time.Duration, authenticate gin.HandlerFunc, jobHandler *JobHandler, runHandler *RunHandler, bulkHandler *BulkHandler, priceHandler *PriceHandler, resultHandler *ResultHandler, alertHandler *AlertHandler, auditHandler *AuditHandler, eventHandler *EventHandler, webhookHandler *WebhookHandler, apiKeyHandler *APIKeyHandler) *gin.Engine {
 	r := gin.Default() // includes Logger + Recovery middleware
+	r.Use(RequestID())
 	r.Use(ErrorHandler())
 
-	api := r.Group("/api/v1")
+	api := r.Group("/api/v1", RequestTimeout(requestTimeout), authenticate)
+
+	read := api.Group("", RequireRole(model.RoleReadOnly, model.RoleOperator), ScopeTenant())
 	{
 		// Job routes
+		read.GET("/jobs/:id", jobHandler.GetJob)
+		read.GET("/jobs", jobHandler.ListJobs)
+		read.GET("/jobs/:id/interval-changes", jobHandler.ListIntervalChanges)
+		read.GET("/jobs/:id/runs", jobHandler.ListRuns)
 
-		api.GET("/jobs/:id", jobHandler.GetJob)
-		api.GET("/jobs", jobHandler.ListJobs)
+		// Operation routes
+		read.GET("/operations/:id", bulkHandler.GetOperation)
 
-		api.POST("/jobs", jobHandler.CreateJob)
+		// Price routes
+		read.GET("/jobs/:id/prices", priceHandler.ListPrices)
 
-		api.POST("/jobs/:id/pause", jobHandler.PauseJob)
-		api.POST("/jobs/:id/resume", jobHandler.ResumeJob)
+		// Alert routes
+		read.GET("/alert-rules/:id", alertHandler.GetRule)
+		read.GET("/alert-rules", alertHandler.ListRules)
+		read.GET("/alerts", alertHandler.ListAlerts)
+		read.GET("/alerts/:id/deliveries", alertHandler.ListDeliveries)
 
-		api.DELETE("/jobs/:id", jobHandler.DeleteJob)
+		// Audit routes
+		read.GET("/audit", auditHandler.ListEntries)
+
+		// Webhook routes
+		read.GET("/webhooks/:id", webhookHandler.GetWebhook)
+		read.GET("/webhooks", webhookHandler.ListWebhooks)
+		read.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+	}
+
+	write := api.Group("", RequireRole(model.RoleOperator), ScopeTenant())
+	{
+		// Job routes
+		write.POST("/jobs", jobHandler.CreateJob)
+		write.PATCH("/jobs/:id", jobHandler.UpdateJob)
+
+		write.POST("/jobs/:id/pause", jobHandler.PauseJob)
+		write.POST("/jobs/:id/resume", jobHandler.ResumeJob)
+		write.POST("/jobs/:id/restore", jobHandler.RestoreJob)
+		write.POST("/jobs/:id/run", runHandler.RunJob)
+
+		write.DELETE("/jobs/:id", jobHandler.DeleteJob)
+
+		// Custom methods of the job collection, e.g. POST /jobs:run
+		write.POST("/jobs:method", customMethods(map[string]gin.HandlerFunc{
+			"run":    runHandler.RunJobs,
+			"import": jobHandler.ImportJobs,
+			"bulk":   bulkHandler.BulkJobs,
+		}))
+
+		// Alert routes
+		write.POST("/alert-rules", alertHandler.CreateRule)
+		write.DELETE("/alert-rules/:id", alertHandler.DeleteRule)
+
+		// Webhook routes
+		write.POST("/webhooks", webhookHandler.CreateWebhook)
+		write.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		write.POST("/webhooks/:id/enable", webhookHandler.EnableWebhook)
+	}
+
+	worker := api.Group("", RequireRole(model.RoleWorker), ScopeTenant())
+	{
+		// Worker result routes
+		worker.POST("/jobs/:id/results", resultHandler.ReportResult)
+	}
+
+	admin := api.Group("", RequireRole(model.RoleAdmin), ScopeTenant())
+	{
+		// API key routes
+		admin.GET("/api-keys/:id", apiKeyHandler.GetKey)
+		admin.GET("/api-keys", apiKeyHandler.ListKeys)
+		admin.POST("/api-keys", apiKeyHandler.CreateKey)
+		admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeKey)
+	}
+
+	// Exports and event streams are served for as long as the client reads them, without the request timeout
+	stream := r.Group("/api/v1", authenticate, RequireRole(model.RoleReadOnly, model.RoleOperator), ScopeTenant())
+	{
+		stream.GET("/jobs:method", customMethods(map[string]gin.HandlerFunc{
+			"export": jobHandler.ExportJobs,
+		}))
+		stream.GET("/jobs/:id/prices:method", customMethods(map[string]gin.HandlerFunc{
+			"export": priceHandler.ExportPrices,
+		}))
+
+		stream.GET("/events", eventHandler.StreamEvents)
 	}
 
 	// You can also add middleware here
@@ -29,3 +115,22 @@ func SetupRouter(jobHandler *JobHandler) *gin.Engine {
 
 	return r
 }
+
+// customMethods routes the custom methods of a collection, e.g. POST /jobs:run,
+// to their handlers by name. gin has no literal colons in paths, the method is
+// captured as the parameter "method".
+func customMethods(methods map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("method"), ":")
+		handler, ok := methods[name]
+		if !ok {
+			c.Error(&service.AppError{
+				Message: fmt.Sprintf("unknown method: %s", name),
+				Code:    "NOT_FOUND",
+				Status:  404,
+			})
+			return
+		}
+		handler(c)
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type RunHandler struct {
+	Svc *service.RunService
+}
+
+func NewRunHandler(svc *service.RunService) *RunHandler {
+	return &RunHandler{
+		Svc: svc,
+	}
+}
+
+// RunJob dispatches a job immediately without changing its schedule and returns the run.
+func (h *RunHandler) RunJob(c *gin.Context) {
+	id, err := parseJobID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	runResp, err := h.Svc.RunJob(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, runResp)
+}
+
+// RunJobs dispatches the jobs matching the selector immediately.
+// Jobs in progress are skipped.
+func (h *RunHandler) RunJobs(c *gin.Context) {
+	var req model.RunJobsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp, err := h.Svc.RunJobs(c.Request.Context(), actorFromRequest(c, model.ActorAnonymous), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+type WebhookHandler struct {
+	Svc *service.WebhookService
+}
+
+func NewWebhookHandler(svc *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		Svc: svc,
+	}
+}
+
+// CreateWebhook subscribes a URL to the job lifecycle events, all event types if none are given.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookResp, err := h.Svc.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookResp)
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookResp, err := h.Svc.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResp)
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	var filter model.ListWebhooksFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedWebhooks, err := h.Svc.ListWebhooks(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedWebhooks)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.Svc.DeleteWebhook(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// EnableWebhook re-enables a webhook disabled after repeated failed deliveries.
+func (h *WebhookHandler) EnableWebhook(c *gin.Context) {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhookResp, err := h.Svc.EnableWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResp)
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter model.ListWebhookDeliveriesFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginatedDeliveries, err := h.Svc.ListDeliveries(c.Request.Context(), id, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedDeliveries)
+}
+
+func parseWebhookID(c *gin.Context) (int, error) {
+	id := c.Param("id")
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, &service.AppError{
+			Message: fmt.Sprintf("invalid webhook id: %s", id),
+			Code:    "INVALID_WEBHOOK_ID",
+			Status:  400,
+		}
+	}
+	return idNum, nil
+}
@@ -0,0 +1,115 @@
+package model
+
+import "time"
+
+// AlertRuleType defines the condition an alert rule reacts to.
+//
+// Rule types:
+//   - PriceBelow: The price dropped below Threshold.
+//   - PriceChange: The price changed by more than Percent compared to the previous observation.
+//   - BackInStock: The product became available again.
+//   - OutOfStock: The product is no longer available.
+type AlertRuleType string
+
+const (
+	AlertRulePriceBelow  AlertRuleType = "price_below"
+	AlertRulePriceChange AlertRuleType = "price_change"
+	AlertRuleBackInStock AlertRuleType = "back_in_stock"
+	AlertRuleOutOfStock  AlertRuleType = "out_of_stock"
+)
+
+func (t AlertRuleType) IsValid() bool {
+	switch t {
+	case AlertRulePriceBelow, AlertRulePriceChange, AlertRuleBackInStock, AlertRuleOutOfStock:
+		return true
+	}
+	return false
+}
+
+// AlertRule is evaluated whenever a new price observation arrives for a job it applies to.
+// A rule either targets a single job (JobID) or all jobs carrying a tag (Tag),
+// in both cases only jobs of the tenant of the rule.
+type AlertRule struct {
+	ID        uint          `gorm:"primaryKey;autoIncrement"`
+	TenantID  string        `gorm:"type:varchar(64);not null;default:'default';index"`
+	JobID     *uint         `gorm:"index"`
+	Tag       *string       `gorm:"type:varchar(50);index"`
+	Type      AlertRuleType `gorm:"type:varchar(20);not null"`
+	Threshold *int64
+	Percent   *float64
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// AppliesTo reports whether the rule targets the given job.
+func (r *AlertRule) AppliesTo(job *Job) bool {
+	if r.TenantID != job.TenantID {
+		return false
+	}
+	if r.JobID != nil {
+		return *r.JobID == job.ID
+	}
+	return r.Tag != nil && job.Tags.Contains(*r.Tag)
+}
+
+// Evaluate reports whether the transition from prev to curr triggers the rule.
+// Rules are edge-triggered: a rule only fires when the condition becomes true,
+// not on every observation while it stays true. Without a previous observation
+// nothing can have changed, so no rule fires.
+func (r *AlertRule) Evaluate(prev, curr *PriceObservation) bool {
+	if prev == nil {
+		return false
+	}
+
+	switch r.Type {
+	case AlertRulePriceBelow:
+		return r.Threshold != nil && curr.Amount < *r.Threshold && prev.Amount >= *r.Threshold
+	case AlertRulePriceChange:
+		if r.Percent == nil || prev.Amount == 0 || prev.Currency != curr.Currency {
+			return false
+		}
+		change := float64(curr.Amount-prev.Amount) / float64(prev.Amount) * 100
+		if change < 0 {
+			change = -change
+		}
+		return change > *r.Percent
+	case AlertRuleBackInStock:
+		return curr.Availability == AvailabilityInStock && prev.Availability == AvailabilityOutOfStock
+	case AlertRuleOutOfStock:
+		return curr.Availability == AvailabilityOutOfStock && prev.Availability == AvailabilityInStock
+	}
+	return false
+}
+
+// AlertEvent records a triggered alert rule.
+// The pair (RuleID, ObservationID) is unique, so the same change never fires twice,
+// even if an observation is processed more than once.
+type AlertEvent struct {
+	ID                   uint          `gorm:"primaryKey;autoIncrement"`
+	RuleID               uint          `gorm:"not null;uniqueIndex:idx_alert_events_rule_observation,priority:1"`
+	ObservationID        uint          `gorm:"not null;uniqueIndex:idx_alert_events_rule_observation,priority:2"`
+	JobID                uint          `gorm:"not null;index"`
+	Type                 AlertRuleType `gorm:"type:varchar(20);not null"`
+	PreviousAmount       int64
+	Amount               int64
+	Currency             string       `gorm:"type:char(3)"`
+	PreviousAvailability Availability `gorm:"type:varchar(20)"`
+	Availability         Availability `gorm:"type:varchar(20)"`
+	TriggeredAt          time.Time    `gorm:"not null;index"`
+}
+
+// NewAlertEvent creates the event for a rule triggered by the transition from prev to curr.
+func NewAlertEvent(rule *AlertRule, prev, curr *PriceObservation) *AlertEvent {
+	return &AlertEvent{
+		RuleID:               rule.ID,
+		ObservationID:        curr.ID,
+		JobID:                curr.JobID,
+		Type:                 rule.Type,
+		PreviousAmount:       prev.Amount,
+		Amount:               curr.Amount,
+		Currency:             curr.Currency,
+		PreviousAvailability: prev.Availability,
+		Availability:         curr.Availability,
+		TriggeredAt:          curr.ObservedAt,
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import "time"
+
+type CreateAlertRuleRequest struct {
+	JobID     *uint         `json:"jobId" binding:"required_without=Tag,excluded_with=Tag"`
+	Tag       *string       `json:"tag" binding:"required_without=JobID,omitempty,min=1,max=50"`
+	Type      AlertRuleType `json:"type" binding:"required,alertruletype"`
+	Threshold *int64        `json:"threshold" binding:"required_if=Type price_below,omitempty,min=0"`
+	Percent   *float64      `json:"percent" binding:"required_if=Type price_change,omitempty,gt=0"`
+}
+
+type AlertRuleResponse struct {
+	ID        uint          `json:"id"`
+	JobID     *uint         `json:"jobId,omitempty"`
+	Tag       *string       `json:"tag,omitempty"`
+	Type      AlertRuleType `json:"type"`
+	Threshold *int64        `json:"threshold,omitempty"`
+	Percent   *float64      `json:"percent,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+type ListAlertRulesFilter struct {
+	JobID *uint   `json:"jobId" form:"jobId"`
+	Tag   *string `json:"tag" form:"tag"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type ListAlertsFilter struct {
+	JobID  *uint          `json:"jobId" form:"jobId"`
+	RuleID *uint          `json:"ruleId" form:"ruleId"`
+	Type   *AlertRuleType `json:"type" form:"type" binding:"omitempty,alertruletype"`
+
+	// Time range of TriggeredAt, From is inclusive and To is exclusive
+	From *time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type AlertEventResponse struct {
+	ID                   uint          `json:"id"`
+	RuleID               uint          `json:"ruleId"`
+	ObservationID        uint          `json:"observationId"`
+	JobID                uint          `json:"jobId"`
+	Type                 AlertRuleType `json:"type"`
+	PreviousAmount       int64         `json:"previousAmount"`
+	Amount               int64         `json:"amount"`
+	Currency             string        `json:"currency"`
+	PreviousAvailability Availability  `json:"previousAvailability"`
+	Availability         Availability  `json:"availability"`
+	TriggeredAt          time.Time     `json:"triggeredAt"`
+}
+
+type PaginatedAlertRulesResponse struct {
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"pageSize"`
+	TotalCount int64                `json:"totalCount"`
+	TotalPages int                  `json:"totalPages"`
+	Items      []*AlertRuleResponse `json:"items"`
+}
+
+type PaginatedAlertsResponse struct {
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"pageSize"`
+	TotalCount int64                 `json:"totalCount"`
+	TotalPages int                   `json:"totalPages"`
+	Items      []*AlertEventResponse `json:"items"`
+}
+
+func ToAlertRuleResponse(r *AlertRule) *AlertRuleResponse {
+	return &AlertRuleResponse{
+		ID:        r.ID,
+		JobID:     r.JobID,
+		Tag:       r.Tag,
+		Type:      r.Type,
+		Threshold: r.Threshold,
+		Percent:   r.Percent,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func ToAlertEventResponse(e *AlertEvent) *AlertEventResponse {
+	return &AlertEventResponse{
+		ID:                   e.ID,
+		RuleID:               e.RuleID,
+		ObservationID:        e.ObservationID,
+		JobID:                e.JobID,
+		Type:                 e.Type,
+		PreviousAmount:       e.PreviousAmount,
+		Amount:               e.Amount,
+		Currency:             e.Currency,
+		PreviousAvailability: e.PreviousAvailability,
+		Availability:         e.Availability,
+		TriggeredAt:          e.TriggeredAt,
+	}
+}
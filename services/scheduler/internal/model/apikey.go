@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// Role grants access to a group of API endpoints.
+type Role string
+
+const (
+	// RoleAdmin has full access, including the management of API keys.
+	RoleAdmin Role = "admin"
+	// RoleOperator manages jobs, alerts and webhooks.
+	RoleOperator Role = "operator"
+	// RoleReadOnly can only query.
+	RoleReadOnly Role = "read_only"
+	// RoleWorker can only report the results of the runs of the jobs of its tenant.
+	RoleWorker Role = "worker"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleOperator, RoleReadOnly, RoleWorker:
+		return true
+	}
+	return false
+}
+
+// APIKey authenticates a machine client. Only the SHA-256 hash of the key is stored,
+// the key itself is returned once when it is created.
+type APIKey struct {
+	ID   uint   `gorm:"primaryKey;autoIncrement"`
+	Name string `gorm:"type:varchar(100);not null"`
+	// Prefix is the start of the key, to recognize it in listings
+	Prefix    string `gorm:"type:varchar(20);not null"`
+	KeyHash   string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Role      Role   `gorm:"type:varchar(20);not null"`
+	TenantID  string `gorm:"type:varchar(64);not null;default:'default'"`
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject names the caller, e.g. the name of its API key. It is empty if
+	// authentication is disabled.
+	Subject string
+	Role    Role
+	// Tenant owns the jobs the caller can access, DefaultTenant if the credentials name no tenant.
+	Tenant string
+}
+
+// Allows reports whether the principal has one of the roles. Admins are allowed everything.
+func (p *Principal) Allows(roles ...Role) bool {
+	if p.Role == RoleAdmin {
+		return true
+	}
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Role Role   `json:"role" binding:"required,role"`
+	// Tenant owns the jobs the key can access, it must be the tenant of the caller if set
+	Tenant string `json:"tenant" binding:"omitempty,tenant"`
+}
+
+type APIKeyResponse struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      Role       `json:"role"`
+	Tenant    string     `json:"tenant"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+}
+
+// CreatedAPIKeyResponse contains the key itself, it is only returned when the key is created.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type ListAPIKeysFilter struct {
+	Revoked *bool `json:"revoked" form:"revoked"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type PaginatedAPIKeysResponse struct {
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+	TotalCount int64             `json:"totalCount"`
+	TotalPages int               `json:"totalPages"`
+	Items      []*APIKeyResponse `json:"items"`
+}
+
+func ToAPIKeyResponse(k *APIKey) *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Role:      k.Role,
+		Tenant:    k.TenantID,
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
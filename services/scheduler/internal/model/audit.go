@@ -0,0 +1,153 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// AuditAction is the kind of change recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionUpdate       AuditAction = "update"
+	AuditActionPause        AuditAction = "pause"
+	AuditActionResume       AuditAction = "resume"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionRestore      AuditAction = "restore"
+	AuditActionStatusChange AuditAction = "status_change"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionPause, AuditActionResume,
+		AuditActionDelete, AuditActionRestore, AuditActionStatusChange:
+		return true
+	}
+	return false
+}
+
+// Actors of changes not made through the API.
+const (
+	ActorScheduler = "scheduler"
+	ActorWorker    = "worker"
+	ActorAnonymous = "anonymous"
+)
+
+// Actor identifies who made a change and the request it was made in.
+type Actor struct {
+	Name      string
+	RequestID string
+}
+
+// FieldChange is the value of a field before and after a change.
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges are the changed fields of a job by their JSON name.
+// They are stored as a JSON object.
+type AuditChanges map[string]FieldChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]FieldChange(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *AuditChanges) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = AuditChanges{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into AuditChanges", src)
+	}
+	return json.Unmarshal(b, (*map[string]FieldChange)(c))
+}
+
+// AuditEntry records a single change of a job. Entries are append-only.
+type AuditEntry struct {
+	ID        uint         `gorm:"primaryKey;autoIncrement"`
+	JobID     uint         `gorm:"not null;index"`
+	Action    AuditAction  `gorm:"type:varchar(20);not null"`
+	Actor     string       `gorm:"type:varchar(100);not null;index"`
+	RequestID string       `gorm:"type:varchar(64)"`
+	Changes   AuditChanges `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time    `gorm:"not null;index"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+// NewAuditEntry records the change of a job from before to after.
+// before is nil for created jobs.
+func NewAuditEntry(actor Actor, action AuditAction, before, after *Job) *AuditEntry {
+	return &AuditEntry{
+		JobID:     after.ID,
+		Action:    action,
+		Actor:     actor.Name,
+		RequestID: actor.RequestID,
+		Changes:   DiffJobs(before, after),
+		CreatedAt: time.Now(),
+	}
+}
+
+// auditIgnoredFields change with every update and are not recorded.
+var auditIgnoredFields = map[string]bool{
+	"updatedAt": true,
+	"version":   true,
+}
+
+// DiffJobs returns the fields that differ between before and after, using the
+// names and formats of the API. If before is nil, all fields of after are returned.
+func DiffJobs(before, after *Job) AuditChanges {
+	prev := jobFields(before)
+	curr := jobFields(after)
+
+	changes := AuditChanges{}
+	for field, value := range curr {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if old, ok := prev[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = FieldChange{Before: prev[field], After: value}
+		}
+	}
+	for field, old := range prev {
+		if _, ok := curr[field]; !ok && !auditIgnoredFields[field] {
+			changes[field] = FieldChange{Before: old}
+		}
+	}
+	return changes
+}
+
+// jobFields returns the fields of the API representation of a job.
+func jobFields(job *Job) map[string]any {
+	fields := map[string]any{}
+	if job == nil {
+		return fields
+	}
+
+	b, err := json.Marshal(ToJobResponse(job))
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(b, &fields)
+	return fields
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+type AuditEntryResponse struct {
+	ID        uint                   `json:"id"`
+	JobID     uint                   `json:"jobId"`
+	Action    AuditAction            `json:"action"`
+	Actor     string                 `json:"actor"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type ListAuditFilter struct {
+	JobID  *uint        `json:"jobId" form:"jobId"`
+	Actor  *string      `json:"actor" form:"actor"`
+	Action *AuditAction `json:"action" form:"action" binding:"omitempty,auditaction"`
+
+	// Time range of CreatedAt, From is inclusive and To is exclusive
+	From *time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type PaginatedAuditResponse struct {
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"pageSize"`
+	TotalCount int64                 `json:"totalCount"`
+	TotalPages int                   `json:"totalPages"`
+	Items      []*AuditEntryResponse `json:"items"`
+}
+
+func ToAuditEntryResponse(e *AuditEntry) *AuditEntryResponse {
+	changes := e.Changes
+	if changes == nil {
+		changes = AuditChanges{}
+	}
+
+	return &AuditEntryResponse{
+		ID:        e.ID,
+		JobID:     e.JobID,
+		Action:    e.Action,
+		Actor:     e.Actor,
+		RequestID: e.RequestID,
+		Changes:   changes,
+		CreatedAt: e.CreatedAt,
+	}
+}
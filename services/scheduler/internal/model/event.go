@@ -0,0 +1,84 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// EventType is the kind of change in the lifecycle of a job.
+type EventType string
+
+// Event types:
+//   - JobCreated: The job was created.
+//   - JobDispatched: A run of the job was submitted to the worker queue, by the scheduler or on request.
+//   - JobCompleted: A worker reported a successful run, including runs finding the page not modified.
+//   - JobFailed: A worker reported a failed run, the job may be retried.
+//   - JobPaused: The job was paused.
+//   - PriceChanged: A run observed a different price or availability than the previous run.
+const (
+	EventJobCreated    EventType = "job.created"
+	EventJobDispatched EventType = "job.dispatched"
+	EventJobCompleted  EventType = "job.completed"
+	EventJobFailed     EventType = "job.failed"
+	EventJobPaused     EventType = "job.paused"
+	EventPriceChanged  EventType = "price.changed"
+)
+
+func (t EventType) IsValid() bool {
+	switch t {
+	case EventJobCreated, EventJobDispatched, EventJobCompleted, EventJobFailed, EventJobPaused, EventPriceChanged:
+		return true
+	}
+	return false
+}
+
+// Event is a change in the lifecycle of a job, published to the subscribers of the event stream.
+// Events are kept in memory only.
+type Event struct {
+	// ID is assigned on publishing, it increases with every event
+	ID   uint64
+	Type EventType
+	Time time.Time
+
+	JobID    uint
+	TenantID string
+	Tags     []string
+
+	// RunID is the run dispatched, completed or failed
+	RunID *uint
+	// Error is the reason a run failed
+	Error string
+
+	// Job is the state of the job after the change
+	Job *JobResponse
+	// Price is the new observation of price events
+	Price *PriceObservationResponse
+}
+
+// NewJobEvent returns an event of job in its current state.
+func NewJobEvent(eventType EventType, job *Job) *Event {
+	return &Event{
+		Type:     eventType,
+		Time:     time.Now(),
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		Tags:     slices.Clone(job.Tags),
+		Job:      ToJobResponse(job),
+	}
+}
+
+// NewRunEvent returns an event of the run of job in the current state of the job.
+func NewRunEvent(eventType EventType, job *Job, run *JobRun) *Event {
+	event := NewJobEvent(eventType, job)
+	runID := run.ID
+	event.RunID = &runID
+	event.Error = run.Error
+	return event
+}
+
+// NewPriceEvent returns the event of a new observation of job.
+func NewPriceEvent(job *Job, observation *PriceObservation) *Event {
+	event := NewJobEvent(EventPriceChanged, job)
+	event.Price = ToPriceObservationResponse(observation)
+	return event
+}
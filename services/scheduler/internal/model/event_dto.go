@@ -0,0 +1,54 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// EventFilter selects the events of a stream, all events if empty.
+type EventFilter struct {
+	JobID *uint   `form:"jobId"`
+	Tag   *string `form:"tag"`
+
+	// TenantID selects the events of the jobs of a tenant, it is set from the credentials of the subscriber
+	TenantID string `form:"-"`
+}
+
+// Matches reports whether the event is selected by the filter.
+func (f *EventFilter) Matches(e *Event) bool {
+	if f.TenantID != "" && e.TenantID != f.TenantID {
+		return false
+	}
+	if f.JobID != nil && e.JobID != *f.JobID {
+		return false
+	}
+	if f.Tag != nil && !slices.Contains(e.Tags, *f.Tag) {
+		return false
+	}
+	return true
+}
+
+type EventResponse struct {
+	ID    uint64       `json:"id"`
+	Type  EventType    `json:"type"`
+	Time  time.Time    `json:"time"`
+	JobID uint         `json:"jobId"`
+	RunID *uint        `json:"runId,omitempty"`
+	Error string       `json:"error,omitempty"`
+	Job   *JobResponse `json:"job"`
+
+	Price *PriceObservationResponse `json:"price,omitempty"`
+}
+
+func ToEventResponse(e *Event) *EventResponse {
+	return &EventResponse{
+		ID:    e.ID,
+		Type:  e.Type,
+		Time:  e.Time,
+		JobID: e.JobID,
+		RunID: e.RunID,
+		Error: e.Error,
+		Job:   e.Job,
+		Price: e.Price,
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+const (
+	// AdaptiveStableRuns is the number of consecutive unchanged runs after which
+	// the interval of an adaptive job is lengthened.
+	AdaptiveStableRuns = 3
+
+	// adaptiveShrinkFactor is applied to the interval when the price changed
+	adaptiveShrinkFactor = 0.5
+	// adaptiveGrowthFactor is applied to the interval after AdaptiveStableRuns unchanged runs
+	adaptiveGrowthFactor = 1.5
+)
+
+// IntervalChangeReason describes why the interval of a job changed.
+type IntervalChangeReason string
+
+const (
+	IntervalReasonPriceChanged IntervalChangeReason = "price_changed"
+	IntervalReasonStable       IntervalChangeReason = "stable"
+	IntervalReasonManual       IntervalChangeReason = "manual"
+)
+
+// IntervalChange records a change of a job's interval so the decisions can be audited.
+type IntervalChange struct {
+	ID          uint                 `gorm:"primaryKey;autoIncrement"`
+	JobID       uint                 `gorm:"not null;index"`
+	OldInterval time.Duration        `gorm:"not null"`
+	NewInterval time.Duration        `gorm:"not null"`
+	Reason      IntervalChangeReason `gorm:"type:varchar(20);not null"`
+	ChangedAt   time.Time            `gorm:"not null"`
+}
+
+// AdaptInterval adjusts the interval of an adaptive job after a successful run.
+// The interval is shortened if the price changed and lengthened after
+// AdaptiveStableRuns consecutive unchanged runs, always bounded by MinInterval
+// and MaxInterval. It returns the recorded change, or nil if the interval was kept.
+func (j *Job) AdaptInterval(priceChanged bool) *IntervalChange {
+	if !j.Adaptive {
+		return nil
+	}
+
+	if priceChanged {
+		j.StableRuns = 0
+		return j.UpdateInterval(j.boundInterval(scale(j.Interval, adaptiveShrinkFactor)), IntervalReasonPriceChanged)
+	}
+
+	j.StableRuns++
+	if j.StableRuns < AdaptiveStableRuns {
+		return nil
+	}
+	j.StableRuns = 0
+	return j.UpdateInterval(j.boundInterval(scale(j.Interval, adaptiveGrowthFactor)), IntervalReasonStable)
+}
+
+func (j *Job) boundInterval(interval time.Duration) time.Duration {
+	if j.MinInterval > 0 && interval < j.MinInterval {
+		return j.MinInterval
+	}
+	if j.MaxInterval > 0 && interval > j.MaxInterval {
+		return j.MaxInterval
+	}
+	return interval
+}
+
+func scale(d time.Duration, factor float64) time.Duration {
+	return time.Duration(float64(d) * factor).Round(time.Second)
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type IntervalChangeResponse struct {
+	ID          uint                 `json:"id"`
+	JobID       uint                 `json:"jobId"`
+	OldInterval string               `json:"oldInterval"`
+	NewInterval string               `json:"newInterval"`
+	Reason      IntervalChangeReason `json:"reason"`
+	ChangedAt   time.Time            `json:"changedAt"`
+}
+
+type ListIntervalChangesFilter struct {
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type PaginatedIntervalChangesResponse struct {
+	Page       int                       `json:"page"`
+	PageSize   int                       `json:"pageSize"`
+	TotalCount int64                     `json:"totalCount"`
+	TotalPages int                       `json:"totalPages"`
+	Items      []*IntervalChangeResponse `json:"items"`
+}
+
+func ToIntervalChangeResponse(c *IntervalChange) *IntervalChangeResponse {
+	return &IntervalChangeResponse{
+		ID:          c.ID,
+		JobID:       c.JobID,
+		OldInterval: c.OldInterval.String(),
+		NewInterval: c.NewInterval.String(),
+		Reason:      c.Reason,
+		ChangedAt:   c.ChangedAt,
+	}
+}
@@ -1,14 +1,25 @@
 package model
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
+
+	"github.com/lorenzhoerb/cogniprice/shared/revalidation"
 )
 
 var ErrCannotPause = errors.New("cannot pause job in current state")
 
+// Priorities of jobs, jobs have the default priority unless another is set.
+const (
+	MinPriority     = -10
+	DefaultPriority = 0
+	MaxPriority     = 10
+)
+
 // JobStatus represents the current status of a job.
 //
 // Status values:
@@ -46,22 +57,95 @@ func (s *JobStatus) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Tags are free-form labels used to group jobs, e.g. by retailer.
+// They are stored as a JSON array.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (t *Tags) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*t = Tags{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+func (t Tags) Contains(tag string) bool {
+	return slices.Contains(t, tag)
+}
+
 // Job represent a crawl job are dispatched regularly.
+// Jobs belong to a tenant, their URLs are unique among the jobs of the tenant that are not deleted.
 type Job struct {
 	ID             uint      `gorm:"primaryKey;autoIncrement"`
-	URL            string    `grom:"not null;uniqueIndex"`
+	TenantID       string    `gorm:"type:varchar(64);not null;default:'default';uniqueIndex:idx_jobs_tenant_url,priority:1,where:deleted_at IS NULL"`
+	URL            string    `gorm:"not null;uniqueIndex:idx_jobs_tenant_url,priority:2,where:deleted_at IS NULL"`
 	RetryAttempts  int       `gorm:"default:0;check:retry_attempts >= 0"`
 	Status         JobStatus `gorm:"type:varchar(20);not null"`
+	Tags           Tags      `gorm:"type:jsonb;not null;default:'[]'"`
 	Interval       time.Duration
 	PauseRequested bool
-	DispatchedAt   *time.Time
-	NextRunAt      time.Time
-	CreatedAt      time.Time `gorm:"autoCreateTime"`
-	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+	// Priority orders the due jobs, higher priorities are dispatched first.
+	Priority int `gorm:"type:smallint;not null;check:priority BETWEEN -10 AND 10"`
+
+	// Adaptive jobs shorten their interval after price changes and lengthen
+	// it after stable runs, bounded by MinInterval and MaxInterval.
+	Adaptive          bool
+	MinInterval       time.Duration
+	MaxInterval       time.Duration
+	StableRuns        int                  `gorm:"default:0"`
+	IntervalReason    IntervalChangeReason `gorm:"type:varchar(20)"`
+	IntervalChangedAt *time.Time
+
+	DispatchedAt *time.Time
+	NextRunAt    time.Time
+
+	// Validators of the last crawl, sent to the worker for conditional requests
+	ETag         string `gorm:"column:etag;type:varchar(255)"`
+	LastModified string `gorm:"type:varchar(64)"`
+	ContentHash  string `gorm:"type:varchar(64)"`
+
+	// NotModifiedCount counts the runs in which the page did not change
+	NotModifiedCount int `gorm:"default:0"`
+
+	// Version is incremented on every update. Updates only succeed if the
+	// stored version still matches, so concurrent changes are not lost.
+	Version   int64     `gorm:"not null;default:1"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	// DeletedAt is set when the job is soft deleted. Deleted jobs are not
+	// scheduled and are purged with their history after the retention period.
+	DeletedAt *time.Time `gorm:"index"`
 }
 
+// IsDue reports whether the job is scheduled, not deleted and its next run is not in the future.
 func (j *Job) IsDue() bool {
-	return j.NextRunAt.After(time.Now())
+	return j.Status == JobStatusScheduled && !j.IsDeleted() && !j.NextRunAt.After(time.Now())
+}
+
+// IsDeleted reports whether the job is soft deleted.
+func (j *Job) IsDeleted() bool {
+	return j.DeletedAt != nil
 }
 
 // ScheduleNextRun updates NextRunAt based on Interval
@@ -70,6 +154,15 @@ func (j *Job) ScheduleNextRun() {
 	j.Status = JobStatusScheduled
 }
 
+// RestoreStatus sets the status of a job in progress back to the status it had before the manual run.
+// Jobs no longer in progress (e.g. paused in the meantime) and runs without a previous status are left untouched.
+func (j *Job) RestoreStatus(run *JobRun) {
+	if j.Status != JobStatusInProgress || !run.IsManual() || run.PreviousStatus == "" {
+		return
+	}
+	j.Status = run.PreviousStatus
+}
+
 // Pause sets the job status to Paused if allowed
 func (j *Job) Pause() error {
 	if j.Status == JobStatusFailed {
@@ -90,18 +183,84 @@ func (j *Job) Resume() error {
 	return nil
 }
 
-// UpdateInterval changes the interval and schedules next run
-func (j *Job) UpdateInterval(interval time.Duration) {
+// UpdateInterval changes the interval, records why it changed and schedules next run.
+// It returns the recorded change, or nil if the interval is unchanged.
+func (j *Job) UpdateInterval(interval time.Duration, reason IntervalChangeReason) *IntervalChange {
+	if interval == j.Interval {
+		return nil
+	}
+
+	now := time.Now()
+	change := &IntervalChange{
+		JobID:       j.ID,
+		OldInterval: j.Interval,
+		NewInterval: interval,
+		Reason:      reason,
+		ChangedAt:   now,
+	}
+
 	j.Interval = interval
+	j.IntervalReason = reason
+	j.IntervalChangedAt = &now
 	j.ScheduleNextRun()
+	return change
+}
+
+// Validators returns the validators of the last crawl.
+func (j *Job) Validators() revalidation.Validators {
+	return revalidation.Validators{
+		ETag:         j.ETag,
+		LastModified: j.LastModified,
+		ContentHash:  j.ContentHash,
+	}
+}
+
+// UpdateValidators stores the validators reported by a worker.
+// Empty values keep the previously stored validator.
+func (j *Job) UpdateValidators(v revalidation.Validators) {
+	if v.ETag != "" {
+		j.ETag = v.ETag
+	}
+	if v.LastModified != "" {
+		j.LastModified = v.LastModified
+	}
+	if v.ContentHash != "" {
+		j.ContentHash = v.ContentHash
+	}
 }
 
 func (j *Job) ShouldRetry(maxAttempts int) bool {
 	return j.RetryAttempts < maxAttempts
 }
 
+// RunOutcome is the result a worker reports after executing a dispatched job.
+type RunOutcome string
+
+// Outcome values:
+//   - Success: The page was crawled and a price was extracted.
+//   - NotModified: The page did not change since the last crawl, so extraction was skipped.
+//   - Failure: The page could not be crawled or no price could be extracted.
+const (
+	RunOutcomeSuccess     RunOutcome = "success"
+	RunOutcomeNotModified RunOutcome = "not_modified"
+	RunOutcomeFailure     RunOutcome = "failure"
+)
+
+func (o RunOutcome) IsValid() bool {
+	switch o {
+	case RunOutcomeSuccess, RunOutcomeNotModified, RunOutcomeFailure:
+		return true
+	}
+	return false
+}
+
 type JobDispatched struct {
 	ID           uint
+	RunID        uint
 	URL          string
 	DispatchedAt time.Time
+
+	// Validators of the last crawl, empty on the first run.
+	// Workers fetch the page with revalidation.Fetch to skip unchanged pages.
+	Validators revalidation.Validators
 }
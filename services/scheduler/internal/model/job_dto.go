@@ -1,28 +1,180 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/shared/revalidation"
+)
+
+// IfMatch is the precondition of the If-Match header of a request changing a job.
+// A nil IfMatch, of a request without the header or with "*", is satisfied by every job.
+type IfMatch struct {
+	// Versions are the versions of the strong entity tags of the header, weak tags never match
+	Versions []int64
+}
+
+// Matches reports whether a job with the version satisfies the precondition.
+func (m *IfMatch) Matches(version int64) bool {
+	return m == nil || slices.Contains(m.Versions, version)
+}
 
 type CreateJobRequest struct {
-	URL      string `json:"url" binding:"required,url"`
-	Interval string `json:"interval" binding:"required,interval"`
+	URL      string   `json:"url" binding:"required,url"`
+	Interval string   `json:"interval" binding:"required,interval"`
+	Tags     []string `json:"tags" binding:"omitempty,max=20,dive,required,max=50"`
+	Priority *int     `json:"priority" binding:"omitempty,min=-10,max=10"`
+
+	// Adaptive scheduling, the interval is kept within MinInterval and MaxInterval
+	Adaptive    bool   `json:"adaptive"`
+	MinInterval string `json:"minInterval" binding:"required_if=Adaptive true,omitempty,interval"`
+	MaxInterval string `json:"maxInterval" binding:"required_if=Adaptive true,omitempty,interval"`
+}
+
+// PatchJobRequest is a JSON Merge Patch (RFC 7396) of a job.
+// Absent fields are kept, fields set to null are removed. Only tags, minInterval
+// and maxInterval may be removed, removing the bounds requires adaptive to be false.
+type PatchJobRequest struct {
+	URL      *string   `json:"url" binding:"omitempty,url"`
+	Interval *string   `json:"interval" binding:"omitempty,interval"`
+	Tags     *[]string `json:"tags" binding:"omitempty,max=20,dive,required,max=50"`
+	Priority *int      `json:"priority" binding:"omitempty,min=-10,max=10"`
+
+	Adaptive    *bool   `json:"adaptive"`
+	MinInterval *string `json:"minInterval" binding:"omitempty,interval"`
+	MaxInterval *string `json:"maxInterval" binding:"omitempty,interval"`
+
+	// Null holds the names of the fields set to null
+	Null map[string]bool `json:"-"`
+}
+
+func (r *PatchJobRequest) UnmarshalJSON(b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	r.Null = map[string]bool{}
+	for name, value := range fields {
+		if string(value) == "null" {
+			r.Null[name] = true
+		}
+	}
+
+	// alias without the UnmarshalJSON method
+	type patch PatchJobRequest
+	return json.Unmarshal(b, (*patch)(r))
+}
+
+// ImportJobRow is a row of an imported file, parsed into the request creating its job.
+type ImportJobRow struct {
+	// Line is the line of the row in the file, starting at 1
+	Line int
+	Job  *CreateJobRequest
+
+	// Errors are the parse and validation errors of the row, the row is not imported if there are any
+	Errors []ImportError
+}
+
+// ImportRowStatus is the result of importing a row.
+type ImportRowStatus string
+
+// Status values:
+//   - Created: The job was created.
+//   - Valid: The job would be created, reported instead of Created in a dry run.
+//   - Duplicate: The URL is already used by a job or an earlier row, the row was skipped.
+//   - Invalid: The row could not be parsed or failed validation.
+const (
+	ImportRowCreated   ImportRowStatus = "created"
+	ImportRowValid     ImportRowStatus = "valid"
+	ImportRowDuplicate ImportRowStatus = "duplicate"
+	ImportRowInvalid   ImportRowStatus = "invalid"
+)
+
+type ImportError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportRowResult struct {
+	Line   int             `json:"line"`
+	URL    string          `json:"url,omitempty"`
+	Status ImportRowStatus `json:"status"`
+	JobID  *uint           `json:"jobId,omitempty"`
+	Errors []ImportError   `json:"errors,omitempty"`
+}
+
+// ImportJobsResponse reports the result of every imported row.
+type ImportJobsResponse struct {
+	DryRun     bool               `json:"dryRun"`
+	Total      int                `json:"total"`
+	Created    int                `json:"created"`
+	Duplicates int                `json:"duplicates"`
+	Invalid    int                `json:"invalid"`
+	Rows       []*ImportRowResult `json:"rows"`
+}
+
+// ReportResultRequest is sent by a worker once it finished crawling a dispatched job.
+// Price fields are required when the outcome is a success.
+// ETag, LastModified and ContentHash are stored for conditional requests of the next run.
+type ReportResultRequest struct {
+	// RunID identifies the run the result belongs to, defaults to the latest unfinished run of the job
+	RunID        *uint         `json:"runId"`
+	WorkerID     string        `json:"workerId" binding:"omitempty,max=100"`
+	StartedAt    *time.Time    `json:"startedAt"`
+	HTTPStatus   *int          `json:"httpStatus" binding:"omitempty,min=100,max=599"`
+	Outcome      RunOutcome    `json:"outcome" binding:"required,runoutcome"`
+	ObservedAt   *time.Time    `json:"observedAt"`
+	Amount       *int64        `json:"amount" binding:"required_if=Outcome success,omitempty,min=0"`
+	Currency     string        `json:"currency" binding:"required_if=Outcome success,omitempty,iso4217"`
+	Availability *Availability `json:"availability" binding:"omitempty,availability"`
+	SnippetHash  string        `json:"snippetHash" binding:"omitempty,max=128"`
+	Error        string        `json:"error" binding:"omitempty,max=1000"`
+	ETag         string        `json:"etag" binding:"omitempty,max=255"`
+	LastModified string        `json:"lastModified" binding:"omitempty,max=64"`
+	ContentHash  string        `json:"contentHash" binding:"omitempty,max=64"`
+}
+
+// Validators returns the validators of the crawled page.
+func (r *ReportResultRequest) Validators() revalidation.Validators {
+	return revalidation.Validators{
+		ETag:         r.ETag,
+		LastModified: r.LastModified,
+		ContentHash:  r.ContentHash,
+	}
 }
 
 type JobResponse struct {
-	ID             uint       `json:"id"`
-	URL            string     `json:"url"`
-	Status         JobStatus  `json:"status"`
-	Interval       string     `json:"interval"`
-	RetryAttempts  int        `json:"retryAttempts"`
-	PauseRequested bool       `json:"pauseRequested"`
-	DispatchedAt   *time.Time `json:"dispatchedAt"`
-	NextRunAt      *time.Time `json:"nextRunAt"`
-	CreatedAt      time.Time  `json:"createdAt"`
-	UpdatedAt      time.Time  `json:"updatedAt"`
+	ID                uint       `json:"id"`
+	TenantID          string     `json:"tenantId"`
+	URL               string     `json:"url"`
+	Status            JobStatus  `json:"status"`
+	Tags              []string   `json:"tags"`
+	Interval          string     `json:"interval"`
+	Priority          int        `json:"priority"`
+	Adaptive          bool       `json:"adaptive"`
+	MinInterval       string     `json:"minInterval,omitempty"`
+	MaxInterval       string     `json:"maxInterval,omitempty"`
+	IntervalReason    string     `json:"intervalReason,omitempty"`
+	IntervalChangedAt *time.Time `json:"intervalChangedAt,omitempty"`
+	RetryAttempts     int        `json:"retryAttempts"`
+	PauseRequested    bool       `json:"pauseRequested"`
+	NotModifiedRuns   int        `json:"notModifiedRuns"`
+	Version           int64      `json:"version"`
+	DispatchedAt      *time.Time `json:"dispatchedAt"`
+	NextRunAt         *time.Time `json:"nextRunAt"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	DeletedAt         *time.Time `json:"deletedAt,omitempty"`
 }
 
 type ListJobsFilter struct {
 	URL    *string    `json:"url" form:"url"`
 	Status *JobStatus `json:"status" form:"status" binding:"omitempty,jobstatus"`
+	Tag    *string    `json:"tag" form:"tag"`
+	// Deleted lists soft deleted jobs instead of active jobs
+	Deleted bool `json:"deleted" form:"deleted"`
 
 	// Pagination
 	PageSize int `json:"pageSize" form:"pageSize"`
@@ -42,16 +194,38 @@ type PaginatedJobsResponse struct {
 }
 
 func ToJobResponse(j *Job) *JobResponse {
+	tags := j.Tags
+	if tags == nil {
+		tags = Tags{}
+	}
+
+	var minInterval, maxInterval string
+	if j.Adaptive {
+		minInterval = j.MinInterval.String()
+		maxInterval = j.MaxInterval.String()
+	}
+
 	return &JobResponse{
-		ID:             j.ID,
-		URL:            j.URL,
-		Status:         j.Status,
-		Interval:       j.Interval.String(),
-		RetryAttempts:  j.RetryAttempts,
-		PauseRequested: j.PauseRequested,
-		DispatchedAt:   j.DispatchedAt,
-		NextRunAt:      &j.NextRunAt,
-		CreatedAt:      j.CreatedAt,
-		UpdatedAt:      j.UpdatedAt,
+		ID:                j.ID,
+		TenantID:          j.TenantID,
+		URL:               j.URL,
+		Status:            j.Status,
+		Tags:              tags,
+		Interval:          j.Interval.String(),
+		Priority:          j.Priority,
+		Adaptive:          j.Adaptive,
+		MinInterval:       minInterval,
+		MaxInterval:       maxInterval,
+		IntervalReason:    string(j.IntervalReason),
+		IntervalChangedAt: j.IntervalChangedAt,
+		RetryAttempts:     j.RetryAttempts,
+		PauseRequested:    j.PauseRequested,
+		NotModifiedRuns:   j.NotModifiedCount,
+		Version:           j.Version,
+		DispatchedAt:      j.DispatchedAt,
+		NextRunAt:         &j.NextRunAt,
+		CreatedAt:         j.CreatedAt,
+		UpdatedAt:         j.UpdatedAt,
+		DeletedAt:         j.DeletedAt,
 	}
 }
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// DeliveryStatus is the final state of delivering an alert to a notification channel.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// NotificationDelivery logs the delivery of an alert event to a single channel.
+type NotificationDelivery struct {
+	ID           uint           `gorm:"primaryKey;autoIncrement"`
+	AlertEventID uint           `gorm:"not null;index"`
+	Channel      string         `gorm:"type:varchar(50);not null"`
+	ChannelType  string         `gorm:"type:varchar(20);not null"`
+	Status       DeliveryStatus `gorm:"type:varchar(20);not null"`
+	Attempts     int            `gorm:"not null"`
+	LastError    string         `gorm:"type:text"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime"`
+	DeliveredAt  *time.Time
+}
+
+type ListDeliveriesFilter struct {
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type NotificationDeliveryResponse struct {
+	ID           uint           `json:"id"`
+	AlertEventID uint           `json:"alertId"`
+	Channel      string         `json:"channel"`
+	ChannelType  string         `json:"channelType"`
+	Status       DeliveryStatus `json:"status"`
+	Attempts     int            `json:"attempts"`
+	LastError    string         `json:"lastError,omitempty"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	DeliveredAt  *time.Time     `json:"deliveredAt"`
+}
+
+type PaginatedDeliveriesResponse struct {
+	Page       int                             `json:"page"`
+	PageSize   int                             `json:"pageSize"`
+	TotalCount int64                           `json:"totalCount"`
+	TotalPages int                             `json:"totalPages"`
+	Items      []*NotificationDeliveryResponse `json:"items"`
+}
+
+func ToNotificationDeliveryResponse(d *NotificationDelivery) *NotificationDeliveryResponse {
+	return &NotificationDeliveryResponse{
+		ID:           d.ID,
+		AlertEventID: d.AlertEventID,
+		Channel:      d.Channel,
+		ChannelType:  d.ChannelType,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		DeliveredAt:  d.DeliveredAt,
+	}
+}
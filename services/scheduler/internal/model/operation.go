@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// BulkAction is the action applied to every job selected by a bulk operation.
+type BulkAction string
+
+const (
+	BulkActionPause  BulkAction = "pause"
+	BulkActionResume BulkAction = "resume"
+	BulkActionDelete BulkAction = "delete"
+)
+
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkActionPause, BulkActionResume, BulkActionDelete:
+		return true
+	}
+	return false
+}
+
+// OperationStatus is the state of an asynchronous operation.
+//
+// Status values:
+//   - Running: The operation is in progress.
+//   - Completed: All jobs were processed, some may have failed.
+//   - Failed: The operation was aborted, e.g. because the jobs could not be selected.
+type OperationStatus string
+
+const (
+	OperationStatusRunning   OperationStatus = "running"
+	OperationStatusCompleted OperationStatus = "completed"
+	OperationStatusFailed    OperationStatus = "failed"
+)
+
+// maxOperationErrors is the number of failed jobs an operation reports in detail.
+const maxOperationErrors = 100
+
+// Operation tracks the progress of a bulk action applied to jobs in the background.
+// Operations are kept in memory only.
+type Operation struct {
+	ID     string
+	Action BulkAction
+	Status OperationStatus
+	// TenantID is the tenant of the selected jobs, only its callers can retrieve the operation
+	TenantID string
+
+	// Total is the number of selected jobs, Processed counts the jobs
+	// the action was applied to so far, successfully or not.
+	Total     int
+	Processed int
+	Succeeded int
+	Failed    int
+
+	// Errors holds the first maxOperationErrors failed jobs
+	Errors []OperationError
+
+	// Error is the reason a failed operation was aborted
+	Error string
+
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// OperationError is a job the action failed for.
+type OperationError struct {
+	JobID   uint   `json:"jobId"`
+	Message string `json:"message"`
+}
+
+// Succeed counts a job the action was applied to.
+func (o *Operation) Succeed() {
+	o.Processed++
+	o.Succeeded++
+}
+
+// Fail counts a job the action failed for.
+func (o *Operation) Fail(jobID uint, err error) {
+	o.Processed++
+	o.Failed++
+	if len(o.Errors) < maxOperationErrors {
+		o.Errors = append(o.Errors, OperationError{JobID: jobID, Message: err.Error()})
+	}
+}
+
+// Finish completes the operation, or fails it if err is not nil.
+func (o *Operation) Finish(err error) {
+	now := time.Now()
+	o.FinishedAt = &now
+	o.Status = OperationStatusCompleted
+	if err != nil {
+		o.Status = OperationStatusFailed
+		o.Error = err.Error()
+	}
+}
+
+// IsFinished reports whether the operation completed or failed.
+func (o *Operation) IsFinished() bool {
+	return o.FinishedAt != nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// BulkJobsRequest applies an action to the jobs selected like ListJobsFilter.
+// ConfirmCount must equal the number of selected jobs, so a selector matching
+// more jobs than expected does not change them.
+type BulkJobsRequest struct {
+	Action BulkAction `json:"action" binding:"required,bulkaction"`
+
+	URL    *string    `json:"url"`
+	Status *JobStatus `json:"status" binding:"omitempty,jobstatus"`
+	Tag    *string    `json:"tag"`
+
+	ConfirmCount *int `json:"confirmCount" binding:"required,min=0"`
+}
+
+type OperationResponse struct {
+	ID         string           `json:"id"`
+	Action     BulkAction       `json:"action"`
+	Status     OperationStatus  `json:"status"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	Errors     []OperationError `json:"errors"`
+	Error      string           `json:"error,omitempty"`
+	CreatedAt  time.Time        `json:"createdAt"`
+	FinishedAt *time.Time       `json:"finishedAt"`
+}
+
+func ToOperationResponse(o *Operation) *OperationResponse {
+	errs := slices.Clone(o.Errors)
+	if errs == nil {
+		errs = []OperationError{}
+	}
+
+	return &OperationResponse{
+		ID:         o.ID,
+		Action:     o.Action,
+		Status:     o.Status,
+		Total:      o.Total,
+		Processed:  o.Processed,
+		Succeeded:  o.Succeeded,
+		Failed:     o.Failed,
+		Errors:     errs,
+		Error:      o.Error,
+		CreatedAt:  o.CreatedAt,
+		FinishedAt: o.FinishedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// Availability represents the stock state of a product at the time it was crawled.
+type Availability string
+
+const (
+	AvailabilityInStock    Availability = "in_stock"
+	AvailabilityOutOfStock Availability = "out_of_stock"
+	AvailabilityUnknown    Availability = "unknown"
+)
+
+func (a Availability) IsValid() bool {
+	switch a {
+	case AvailabilityInStock, AvailabilityOutOfStock, AvailabilityUnknown:
+		return true
+	}
+	return false
+}
+
+// PriceObservation is a single price recorded by a worker for a job.
+// Amount is stored in minor currency units (e.g. cents) to avoid rounding errors.
+type PriceObservation struct {
+	ID           uint         `gorm:"primaryKey;autoIncrement"`
+	JobID        uint         `gorm:"not null;index:idx_price_observations_job_observed,priority:1"`
+	ObservedAt   time.Time    `gorm:"not null;index:idx_price_observations_job_observed,priority:2"`
+	Amount       int64        `gorm:"not null;check:amount >= 0"`
+	Currency     string       `gorm:"type:char(3);not null"`
+	Availability Availability `gorm:"type:varchar(20);not null"`
+	SnippetHash  string       `gorm:"type:varchar(128)"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime"`
+}
+
+// ChangedFrom reports whether price or availability differ from the previous observation.
+// The first observation of a job (prev == nil) is not considered a change.
+func (o *PriceObservation) ChangedFrom(prev *PriceObservation) bool {
+	if prev == nil {
+		return false
+	}
+	return o.Amount != prev.Amount || o.Currency != prev.Currency || o.Availability != prev.Availability
+}
+
+// DailyPrice is the downsampled view of all observations of a job on one day.
+type DailyPrice struct {
+	Day      time.Time
+	Min      int64
+	Max      int64
+	Last     int64
+	Currency string
+	Count    int64
+}
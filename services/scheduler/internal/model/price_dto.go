@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+const (
+	PriceResolutionRaw = "raw"
+	PriceResolutionDay = "day"
+)
+
+type ListPricesFilter struct {
+	// Time range, From is inclusive and To is exclusive
+	From *time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   *time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// Resolution is either "raw" (every observation) or "day" (min/max/last per day)
+	Resolution *string `json:"resolution" form:"resolution" binding:"omitempty,oneof=raw day"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+
+	// Sorting by observation time
+	SortOrder *string `json:"sortOrder" form:"sortOrder" binding:"omitempty,sortorder"`
+}
+
+type PriceObservationResponse struct {
+	ID           uint         `json:"id"`
+	JobID        uint         `json:"jobId"`
+	ObservedAt   time.Time    `json:"observedAt"`
+	Amount       int64        `json:"amount"`
+	Currency     string       `json:"currency"`
+	Availability Availability `json:"availability"`
+	SnippetHash  string       `json:"snippetHash,omitempty"`
+}
+
+type DailyPriceResponse struct {
+	Day      time.Time `json:"day"`
+	Min      int64     `json:"min"`
+	Max      int64     `json:"max"`
+	Last     int64     `json:"last"`
+	Currency string    `json:"currency"`
+	Count    int64     `json:"count"`
+}
+
+type PaginatedPricesResponse struct {
+	Page       int                         `json:"page"`
+	PageSize   int                         `json:"pageSize"`
+	TotalCount int64                       `json:"totalCount"`
+	TotalPages int                         `json:"totalPages"`
+	Items      []*PriceObservationResponse `json:"items"`
+}
+
+type PaginatedDailyPricesResponse struct {
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"pageSize"`
+	TotalCount int64                 `json:"totalCount"`
+	TotalPages int                   `json:"totalPages"`
+	Items      []*DailyPriceResponse `json:"items"`
+}
+
+func ToPriceObservationResponse(o *PriceObservation) *PriceObservationResponse {
+	return &PriceObservationResponse{
+		ID:           o.ID,
+		JobID:        o.JobID,
+		ObservedAt:   o.ObservedAt,
+		Amount:       o.Amount,
+		Currency:     o.Currency,
+		Availability: o.Availability,
+		SnippetHash:  o.SnippetHash,
+	}
+}
+
+func ToDailyPriceResponse(d *DailyPrice) *DailyPriceResponse {
+	return &DailyPriceResponse{
+		Day:      d.Day,
+		Min:      d.Min,
+		Max:      d.Max,
+		Last:     d.Last,
+		Currency: d.Currency,
+		Count:    d.Count,
+	}
+}
@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// RunTrigger is what caused a run.
+type RunTrigger string
+
+// Trigger values:
+//   - Schedule: The job was due and dispatched by the scheduler.
+//   - Manual: The job was dispatched on request, its schedule is not affected by the result.
+const (
+	RunTriggerSchedule RunTrigger = "schedule"
+	RunTriggerManual   RunTrigger = "manual"
+)
+
+// JobRun records a single execution of a job, from its dispatch until the
+// worker reported the result.
+type JobRun struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	JobID        uint      `gorm:"not null;index"`
+	DispatchedAt time.Time `gorm:"not null"`
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	Trigger      RunTrigger `gorm:"column:triggered_by;type:varchar(20);not null;default:schedule"`
+
+	// PreviousStatus is the status of the job before a manual run, it is restored when the run finishes
+	PreviousStatus JobStatus `gorm:"type:varchar(20)"`
+
+	// Outcome is empty until the worker reported the result
+	Outcome    RunOutcome `gorm:"type:varchar(20)"`
+	HTTPStatus *int       `gorm:"column:http_status"`
+	Error      string     `gorm:"type:text"`
+	WorkerID   string     `gorm:"type:varchar(100)"`
+
+	// Duration from start, or dispatch if the start is unknown, until finish
+	Duration time.Duration
+}
+
+// NewJobRun returns the run of a job dispatched at dispatchedAt by the scheduler.
+func NewJobRun(job *Job, dispatchedAt time.Time) *JobRun {
+	return &JobRun{
+		JobID:        job.ID,
+		DispatchedAt: dispatchedAt,
+		Trigger:      RunTriggerSchedule,
+	}
+}
+
+// NewManualJobRun returns the run of a job dispatched on request at dispatchedAt.
+// It must be created before the job is marked in progress, to keep the status to restore.
+func NewManualJobRun(job *Job, dispatchedAt time.Time) *JobRun {
+	return &JobRun{
+		JobID:          job.ID,
+		DispatchedAt:   dispatchedAt,
+		Trigger:        RunTriggerManual,
+		PreviousStatus: job.Status,
+	}
+}
+
+// IsManual reports whether the run was dispatched on request instead of by the scheduler.
+func (r *JobRun) IsManual() bool {
+	return r.Trigger == RunTriggerManual
+}
+
+// IsFinished reports whether the result of the run was reported.
+func (r *JobRun) IsFinished() bool {
+	return r.FinishedAt != nil
+}
+
+// Finish records the reported result of the run.
+func (r *JobRun) Finish(req *ReportResultRequest, finishedAt time.Time) {
+	r.StartedAt = req.StartedAt
+	r.FinishedAt = &finishedAt
+	r.Outcome = req.Outcome
+	r.HTTPStatus = req.HTTPStatus
+	r.Error = req.Error
+	r.WorkerID = req.WorkerID
+
+	start := r.DispatchedAt
+	if r.StartedAt != nil {
+		start = *r.StartedAt
+	}
+	r.Duration = max(finishedAt.Sub(start), 0)
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+type JobRunResponse struct {
+	ID           uint        `json:"id"`
+	JobID        uint        `json:"jobId"`
+	DispatchedAt time.Time   `json:"dispatchedAt"`
+	StartedAt    *time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time  `json:"finishedAt"`
+	Trigger      RunTrigger  `json:"trigger"`
+	Outcome      *RunOutcome `json:"outcome"`
+	HTTPStatus   *int        `json:"httpStatus"`
+	Error        string      `json:"error,omitempty"`
+	WorkerID     string      `json:"workerId,omitempty"`
+	Duration     string      `json:"duration,omitempty"`
+}
+
+type ListRunsFilter struct {
+	Outcome *RunOutcome `json:"outcome" form:"outcome" binding:"omitempty,runoutcome"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+// RunJobsRequest selects the jobs to run immediately, like ListJobsFilter.
+type RunJobsRequest struct {
+	URL    *string    `json:"url"`
+	Status *JobStatus `json:"status" binding:"omitempty,jobstatus"`
+	Tag    *string    `json:"tag"`
+
+	// Limit is the maximum number of jobs to run, defaults to 100
+	Limit int `json:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type RunJobsResponse struct {
+	Runs    []*JobRunResponse `json:"runs"`
+	Skipped []*SkippedJob     `json:"skipped"`
+}
+
+// SkippedJob is a selected job that was not run.
+type SkippedJob struct {
+	JobID  uint   `json:"jobId"`
+	Reason string `json:"reason"`
+}
+
+type PaginatedRunsResponse struct {
+	Page       int               `json:"page"`
+	PageSize   int               `json:"pageSize"`
+	TotalCount int64             `json:"totalCount"`
+	TotalPages int               `json:"totalPages"`
+	Items      []*JobRunResponse `json:"items"`
+}
+
+func ToJobRunResponse(r *JobRun) *JobRunResponse {
+	resp := &JobRunResponse{
+		ID:           r.ID,
+		JobID:        r.JobID,
+		DispatchedAt: r.DispatchedAt,
+		StartedAt:    r.StartedAt,
+		FinishedAt:   r.FinishedAt,
+		Trigger:      r.Trigger,
+		HTTPStatus:   r.HTTPStatus,
+		Error:        r.Error,
+		WorkerID:     r.WorkerID,
+	}
+	if r.IsFinished() {
+		outcome := r.Outcome
+		resp.Outcome = &outcome
+		resp.Duration = r.Duration.String()
+	}
+	return resp
+}
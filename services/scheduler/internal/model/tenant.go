@@ -0,0 +1,45 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// DefaultTenant owns the jobs of callers whose credentials name no tenant,
+// and all jobs created before jobs were isolated per tenant.
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// IsValidTenant reports whether id is a tenant ID: up to 64 lowercase letters,
+// digits, dots, underscores and dashes, starting with a letter or digit.
+func IsValidTenant(id string) bool {
+	return tenantPattern.MatchString(id)
+}
+
+// TenantQuota limits the jobs of a tenant. Zero values are not limited.
+type TenantQuota struct {
+	// MaxJobs is the number of jobs a tenant can have, soft deleted jobs are not counted.
+	MaxJobs int
+	// MinInterval is the shortest interval of the jobs of a tenant,
+	// including the minimum interval of adaptive jobs.
+	MinInterval time.Duration
+}
+
+// TenantQuotas are the quotas of all tenants.
+type TenantQuotas struct {
+	// Default is the quota of tenants without an own quota.
+	Default TenantQuota
+	Tenants map[string]TenantQuota
+}
+
+// For returns the quota of a tenant.
+func (q *TenantQuotas) For(tenant string) TenantQuota {
+	if q == nil {
+		return TenantQuota{}
+	}
+	if quota, ok := q.Tenants[tenant]; ok {
+		return quota
+	}
+	return q.Default
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventTypes are the event types a webhook subscribes to, all types if empty.
+// They are stored as a JSON array.
+type EventTypes []EventType
+
+func (t EventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]EventType(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (t *EventTypes) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*t = EventTypes{}
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into EventTypes", src)
+	}
+	return json.Unmarshal(b, (*[]EventType)(t))
+}
+
+// WebhookSubscription is a consumer that is pushed the events of the job lifecycle.
+// It is only pushed the events of the jobs of its tenant, and disabled after too many
+// consecutive failed deliveries.
+type WebhookSubscription struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement"`
+	TenantID string `gorm:"type:varchar(64);not null;default:'default';index"`
+	URL      string `gorm:"type:varchar(2048);not null"`
+	// Secret signs the deliveries, it is never returned by the API
+	Secret     string     `gorm:"type:varchar(200);not null"`
+	EventTypes EventTypes `gorm:"type:jsonb;not null;default:'[]'"`
+	Enabled    bool       `gorm:"not null"`
+
+	// ConsecutiveFailures counts the failed deliveries since the last successful one
+	ConsecutiveFailures int `gorm:"not null;default:0"`
+	DisabledAt          *time.Time
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Accepts reports whether the webhook subscribes to the type of the event, and the event
+// belongs to a job of the tenant of the webhook.
+func (w *WebhookSubscription) Accepts(event *Event) bool {
+	if event.TenantID != w.TenantID {
+		return false
+	}
+	return len(w.EventTypes) == 0 || slices.Contains(w.EventTypes, event.Type)
+}
+
+// WebhookDelivery logs the delivery of an event to a webhook, including all retries.
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primaryKey;autoIncrement"`
+	SubscriptionID uint           `gorm:"not null;index"`
+	EventID        uint64         `gorm:"not null"`
+	EventType      EventType      `gorm:"type:varchar(30);not null"`
+	JobID          uint           `gorm:"not null"`
+	Status         DeliveryStatus `gorm:"type:varchar(20);not null"`
+	Attempts       int            `gorm:"not null"`
+	// ResponseCode is the HTTP status of the last attempt, nil if no response was received
+	ResponseCode *int
+	LastError    string    `gorm:"type:text"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	DeliveredAt  *time.Time
+}
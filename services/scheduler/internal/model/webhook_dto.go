@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+type CreateWebhookRequest struct {
+	URL        string      `json:"url" binding:"required,url,max=2048"`
+	Secret     string      `json:"secret" binding:"required,min=16,max=200"`
+	EventTypes []EventType `json:"eventTypes" binding:"omitempty,dive,eventtype"`
+}
+
+type WebhookResponse struct {
+	ID                  uint        `json:"id"`
+	URL                 string      `json:"url"`
+	EventTypes          []EventType `json:"eventTypes"`
+	Enabled             bool        `json:"enabled"`
+	ConsecutiveFailures int         `json:"consecutiveFailures"`
+	DisabledAt          *time.Time  `json:"disabledAt"`
+	CreatedAt           time.Time   `json:"createdAt"`
+	UpdatedAt           time.Time   `json:"updatedAt"`
+}
+
+type ListWebhooksFilter struct {
+	Enabled *bool `json:"enabled" form:"enabled"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type PaginatedWebhooksResponse struct {
+	Page       int                `json:"page"`
+	PageSize   int                `json:"pageSize"`
+	TotalCount int64              `json:"totalCount"`
+	TotalPages int                `json:"totalPages"`
+	Items      []*WebhookResponse `json:"items"`
+}
+
+type ListWebhookDeliveriesFilter struct {
+	Status *DeliveryStatus `json:"status" form:"status" binding:"omitempty,oneof=delivered failed"`
+
+	// Pagination
+	PageSize int `json:"pageSize" form:"pageSize"`
+	Page     int `json:"page" form:"page"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID           uint           `json:"id"`
+	WebhookID    uint           `json:"webhookId"`
+	EventID      uint64         `json:"eventId"`
+	EventType    EventType      `json:"eventType"`
+	JobID        uint           `json:"jobId"`
+	Status       DeliveryStatus `json:"status"`
+	Attempts     int            `json:"attempts"`
+	ResponseCode *int           `json:"responseCode"`
+	LastError    string         `json:"lastError,omitempty"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	DeliveredAt  *time.Time     `json:"deliveredAt"`
+}
+
+type PaginatedWebhookDeliveriesResponse struct {
+	Page       int                        `json:"page"`
+	PageSize   int                        `json:"pageSize"`
+	TotalCount int64                      `json:"totalCount"`
+	TotalPages int                        `json:"totalPages"`
+	Items      []*WebhookDeliveryResponse `json:"items"`
+}
+
+func ToWebhookResponse(w *WebhookSubscription) *WebhookResponse {
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = EventTypes{}
+	}
+
+	return &WebhookResponse{
+		ID:                  w.ID,
+		URL:                 w.URL,
+		EventTypes:          eventTypes,
+		Enabled:             w.Enabled,
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		DisabledAt:          w.DisabledAt,
+		CreatedAt:           w.CreatedAt,
+		UpdatedAt:           w.UpdatedAt,
+	}
+}
+
+func ToWebhookDeliveryResponse(d *WebhookDelivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		ID:           d.ID,
+		WebhookID:    d.SubscriptionID,
+		EventID:      d.EventID,
+		EventType:    d.EventType,
+		JobID:        d.JobID,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		CreatedAt:    d.CreatedAt,
+		DeliveredAt:  d.DeliveredAt,
+	}
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"golang.org/x/text/currency"
+)
+
+const (
+	ChannelTypeWebhook = "webhook"
+	ChannelTypeSlack   = "slack"
+	ChannelTypeSMTP    = "smtp"
+
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Channel delivers a notification message to an external system.
+type Channel interface {
+	// Send delivers the message once. Retries are handled by the Notifier.
+	Send(msg *Message) error
+}
+
+// Message is the notification sent for a triggered alert.
+type Message struct {
+	Subject string
+	Text    string
+	Job     *model.Job
+	Event   *model.AlertEvent
+}
+
+// NewMessage builds the message for an alert event of a job.
+func NewMessage(job *model.Job, event *model.AlertEvent) *Message {
+	var text string
+	switch event.Type {
+	case model.AlertRulePriceBelow, model.AlertRulePriceChange:
+		text = fmt.Sprintf("Price of %s changed from %s to %s",
+			job.URL, formatAmount(event.PreviousAmount, event.Currency), formatAmount(event.Amount, event.Currency))
+	case model.AlertRuleBackInStock:
+		text = fmt.Sprintf("%s is back in stock at %s", job.URL, formatAmount(event.Amount, event.Currency))
+	case model.AlertRuleOutOfStock:
+		text = fmt.Sprintf("%s is out of stock", job.URL)
+	}
+
+	return &Message{
+		Subject: fmt.Sprintf("[cogniprice] %s alert for job %d", event.Type, job.ID),
+		Text:    text,
+		Job:     job,
+		Event:   event,
+	}
+}
+
+// formatAmount formats an amount given in minor currency units with the number of
+// minor units of the ISO 4217 currency, e.g. 1999 EUR as "19.99 EUR" and 1234 JPY as "1234 JPY".
+// Amounts of unknown currencies are given in minor units.
+func formatAmount(amount int64, code string) string {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return fmt.Sprintf("%d minor units of %s", amount, code)
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	if scale == 0 {
+		return fmt.Sprintf("%d %s", amount, unit)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := fmt.Sprintf("%0*d", scale+1, amount)
+	return fmt.Sprintf("%s%s.%s %s", sign, digits[:len(digits)-scale], digits[len(digits)-scale:], unit)
+}
+
+// route is a configured channel together with its delivery settings.
+type route struct {
+	name        string
+	channelType string
+	channel     Channel
+	alertTypes  map[model.AlertRuleType]bool
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func (r *route) accepts(event *model.AlertEvent) bool {
+	return len(r.alertTypes) == 0 || r.alertTypes[event.Type]
+}
+
+func newRoute(cfg *config.ChannelConfig) (*route, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("notification channel of type %q has no name", cfg.Type)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	var channel Channel
+	switch cfg.Type {
+	case ChannelTypeWebhook:
+		channel = NewWebhookChannel(cfg.URL, cfg.Secret, timeout)
+	case ChannelTypeSlack:
+		channel = NewSlackChannel(cfg.URL, timeout)
+	case ChannelTypeSMTP:
+		smtpChannel, err := NewSMTPChannel(&cfg.SMTP)
+		if err != nil {
+			return nil, fmt.Errorf("notification channel %s: %w", cfg.Name, err)
+		}
+		channel = smtpChannel
+	default:
+		return nil, fmt.Errorf("notification channel %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+
+	alertTypes := map[model.AlertRuleType]bool{}
+	for _, t := range cfg.AlertTypes {
+		alertType := model.AlertRuleType(t)
+		if !alertType.IsValid() {
+			return nil, fmt.Errorf("notification channel %s: invalid alert type %q", cfg.Name, t)
+		}
+		alertTypes[alertType] = true
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	backoff := cfg.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	return &route{
+		name:        cfg.Name,
+		channelType: cfg.Type,
+		channel:     channel,
+		alertTypes:  alertTypes,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}, nil
+}
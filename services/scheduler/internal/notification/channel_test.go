@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{1999, "EUR", "19.99 EUR"},
+		{5, "usd", "0.05 USD"},
+		{-250, "EUR", "-2.50 EUR"},
+		{1234, "JPY", "1234 JPY"},
+		{12345, "KWD", "12.345 KWD"},
+		{7, "BHD", "0.007 BHD"},
+		{1234, "XYZ", "1234 minor units of XYZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatAmount(tt.amount, tt.currency))
+		})
+	}
+}
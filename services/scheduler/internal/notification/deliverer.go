@@ -0,0 +1,193 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+const (
+	EventTypeHeader = "X-Cogniprice-Event"
+	EventIDHeader   = "X-Cogniprice-Event-Id"
+
+	defaultWebhookQueueSize   = 1000
+	defaultWebhookMaxFailures = 10
+)
+
+// WebhookRepository provides the webhook subscriptions and stores their delivery log.
+type WebhookRepository interface {
+	// EnabledSubscriptions returns all subscriptions that are not disabled.
+	EnabledSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+
+	// SaveDelivery records the delivery and updates the failure count of its subscription,
+	// disabling it after maxFailures consecutive failures. It reports whether it was disabled.
+	SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery, maxFailures int) (bool, error)
+}
+
+// WebhookDeliverer pushes the events of the job lifecycle to the webhook subscriptions.
+// Events are queued and delivered in the background by Run, so publishers are never
+// blocked by slow or failing webhooks.
+type WebhookDeliverer struct {
+	repo        WebhookRepository
+	queue       chan *model.Event
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	maxFailures int
+}
+
+func NewWebhookDeliverer(cfg *config.WebhooksConfig, repo WebhookRepository) *WebhookDeliverer {
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWebhookQueueSize
+	}
+
+	d := &WebhookDeliverer{
+		repo:        repo,
+		queue:       make(chan *model.Event, queueSize),
+		client:      &http.Client{Timeout: cfg.Timeout},
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.Backoff,
+		maxFailures: cfg.MaxFailures,
+	}
+	if d.client.Timeout <= 0 {
+		d.client.Timeout = defaultTimeout
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultMaxAttempts
+	}
+	if d.backoff <= 0 {
+		d.backoff = defaultBackoff
+	}
+	if d.maxFailures <= 0 {
+		d.maxFailures = defaultWebhookMaxFailures
+	}
+	return d
+}
+
+// Publish queues the events for delivery.
+// If the queue is full the events are dropped and logged.
+func (d *WebhookDeliverer) Publish(events ...*model.Event) {
+	for _, event := range events {
+		select {
+		case d.queue <- event:
+		default:
+			log.Printf("[WARN] webhook queue full, dropping event %d\n", event.ID)
+		}
+	}
+}
+
+// Run delivers queued events until ctx is cancelled. An event is sent to all subscribed
+// webhooks concurrently, the next event is delivered once all of them are done,
+// so every webhook receives the events in order.
+func (d *WebhookDeliverer) Run(ctx context.Context) {
+	log.Println("webhook deliverer started")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			d.dispatch(ctx, event)
+		}
+	}
+}
+
+func (d *WebhookDeliverer) dispatch(ctx context.Context, event *model.Event) {
+	subscriptions, err := d.repo.EnabledSubscriptions(ctx)
+	if err != nil {
+		log.Printf("[ERROR] failed to load webhooks for event %d: %v\n", event.ID, err)
+		return
+	}
+
+	body, err := json.Marshal(model.ToEventResponse(event))
+	if err != nil {
+		log.Printf("[ERROR] failed to encode event %d: %v\n", event.ID, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, subscription, event, body)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver posts the event to the webhook, retrying with exponential backoff,
+// and records the outcome in the delivery log.
+func (d *WebhookDeliverer) deliver(ctx context.Context, subscription *model.WebhookSubscription, event *model.Event, body []byte) {
+	delivery := &model.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		JobID:          event.JobID,
+		Status:         model.DeliveryStatusFailed,
+	}
+
+	backoff := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		code, err := d.send(ctx, subscription, event, body)
+		delivery.ResponseCode = nil
+		if code != 0 {
+			delivery.ResponseCode = &code
+		}
+		if err == nil {
+			now := time.Now()
+			delivery.Status = model.DeliveryStatusDelivered
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+			break
+		}
+
+		delivery.LastError = err.Error()
+		log.Printf("[WARN] delivering event %d to webhook %d failed (attempt %d/%d): %v\n",
+			event.ID, subscription.ID, attempt, d.maxAttempts, err)
+
+		if attempt == d.maxAttempts || !wait(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	// the outcome is recorded even if delivery was aborted by shutdown
+	disabled, err := d.repo.SaveDelivery(context.WithoutCancel(ctx), delivery, d.maxFailures)
+	if err != nil {
+		log.Printf("[ERROR] failed to save delivery log of event %d to webhook %d: %v\n", event.ID, subscription.ID, err)
+		return
+	}
+	if disabled {
+		log.Printf("[WARN] webhook %d disabled after %d consecutive failed deliveries\n", subscription.ID, d.maxFailures)
+	}
+}
+
+// send posts the event once, signed with the secret of the webhook.
+func (d *WebhookDeliverer) send(ctx context.Context, subscription *model.WebhookSubscription, event *model.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, string(event.Type))
+	req.Header.Set(EventIDHeader, strconv.FormatUint(event.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	return post(d.client, req)
+}
@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+// deliveryLog is an in-memory DeliveryRepository.
+type deliveryLog struct {
+	mu         sync.Mutex
+	deliveries []model.NotificationDelivery
+}
+
+func (l *deliveryLog) SaveDelivery(_ context.Context, delivery *model.NotificationDelivery) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.deliveries = append(l.deliveries, *delivery)
+	return nil
+}
+
+func (l *deliveryLog) all() []model.NotificationDelivery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]model.NotificationDelivery(nil), l.deliveries...)
+}
+
+func testMessage(alertType model.AlertRuleType) *Message {
+	job := &model.Job{ID: 7, URL: "https://shop.test/product", TenantID: model.DefaultTenant}
+	event := &model.AlertEvent{
+		ID:             3,
+		RuleID:         1,
+		JobID:          7,
+		Type:           alertType,
+		PreviousAmount: 1999,
+		Amount:         1499,
+		Currency:       "EUR",
+		TriggeredAt:    time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+	}
+	return NewMessage(job, event)
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+const defaultQueueSize = 100
+
+// DeliveryRepository stores the delivery log of notifications.
+type DeliveryRepository interface {
+	SaveDelivery(ctx context.Context, delivery *model.NotificationDelivery) error
+}
+
+// Notifier delivers alert events to all configured channels.
+// Alerts are queued and delivered in the background by Run, so callers are never
+// blocked by slow or failing channels.
+type Notifier struct {
+	routes []*route
+	repo   DeliveryRepository
+	queue  chan *Message
+}
+
+func NewNotifier(cfg *config.NotificationConfig, repo DeliveryRepository) (*Notifier, error) {
+	routes := make([]*route, 0, len(cfg.Channels))
+	for i := range cfg.Channels {
+		r, err := newRoute(&cfg.Channels[i])
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	return &Notifier{
+		routes: routes,
+		repo:   repo,
+		queue:  make(chan *Message, queueSize),
+	}, nil
+}
+
+// Notify queues the alert event for delivery.
+// If the queue is full the alert is dropped and logged; the event itself remains stored.
+func (n *Notifier) Notify(job *model.Job, event *model.AlertEvent) {
+	if len(n.routes) == 0 {
+		return
+	}
+
+	select {
+	case n.queue <- NewMessage(job, event):
+	default:
+		log.Printf("[WARN] notification queue full, dropping alert %d\n", event.ID)
+	}
+}
+
+// Run delivers queued alerts until ctx is cancelled.
+func (n *Notifier) Run(ctx context.Context) {
+	log.Printf("notifier started: channels=%d\n", len(n.routes))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-n.queue:
+			for _, r := range n.routes {
+				if r.accepts(msg.Event) {
+					n.deliver(ctx, r, msg)
+				}
+			}
+		}
+	}
+}
+
+// deliver sends the message through the route, retrying with exponential backoff,
+// and records the outcome in the delivery log.
+func (n *Notifier) deliver(ctx context.Context, r *route, msg *Message) {
+	delivery := &model.NotificationDelivery{
+		AlertEventID: msg.Event.ID,
+		Channel:      r.name,
+		ChannelType:  r.channelType,
+		Status:       model.DeliveryStatusFailed,
+	}
+
+	backoff := r.backoff
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		err := r.channel.Send(msg)
+		if err == nil {
+			now := time.Now()
+			delivery.Status = model.DeliveryStatusDelivered
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+			break
+		}
+
+		delivery.LastError = err.Error()
+		log.Printf("[WARN] delivering alert %d to %s failed (attempt %d/%d): %v\n",
+			msg.Event.ID, r.name, attempt, r.maxAttempts, err)
+
+		if attempt == r.maxAttempts || !wait(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	// the outcome is recorded even if delivery was aborted by shutdown
+	if err := n.repo.SaveDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("[ERROR] failed to save delivery log of alert %d: %v\n", msg.Event.ID, err)
+	}
+}
+
+// wait blocks for d and reports false if ctx was cancelled in the meantime.
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
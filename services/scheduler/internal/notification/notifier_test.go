@@ -0,0 +1,162 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer fails the first failures requests with 500 and records the time of every request.
+type flakyServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	requests []time.Time
+}
+
+func newFlakyServer(failures int) *flakyServer {
+	s := &flakyServer{failures: failures}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, time.Now())
+		if len(s.requests) <= s.failures {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	return s
+}
+
+func (s *flakyServer) requestTimes() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.requests...)
+}
+
+func TestNotifierRetriesWithBackoff(t *testing.T) {
+	server := newFlakyServer(2)
+	defer server.Close()
+
+	repo := &deliveryLog{}
+	backoff := 20 * time.Millisecond
+	n, err := NewNotifier(&config.NotificationConfig{Channels: []config.ChannelConfig{
+		{Name: "hook", Type: ChannelTypeWebhook, URL: server.URL, MaxAttempts: 3, Backoff: backoff},
+	}}, repo)
+	require.NoError(t, err)
+
+	n.deliver(context.Background(), n.routes[0], testMessage(model.AlertRulePriceBelow))
+
+	requests := server.requestTimes()
+	require.Len(t, requests, 3)
+	assert.GreaterOrEqual(t, requests[1].Sub(requests[0]), backoff)
+	assert.GreaterOrEqual(t, requests[2].Sub(requests[1]), 2*backoff, "backoff doubles")
+
+	deliveries := repo.all()
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, model.DeliveryStatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Equal(t, uint(3), deliveries[0].AlertEventID)
+	assert.Equal(t, "hook", deliveries[0].Channel)
+	assert.Equal(t, ChannelTypeWebhook, deliveries[0].ChannelType)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	assert.Empty(t, deliveries[0].LastError)
+}
+
+func TestNotifierGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newFlakyServer(10)
+	defer server.Close()
+
+	repo := &deliveryLog{}
+	n, err := NewNotifier(&config.NotificationConfig{Channels: []config.ChannelConfig{
+		{Name: "hook", Type: ChannelTypeWebhook, URL: server.URL, MaxAttempts: 2, Backoff: time.Millisecond},
+	}}, repo)
+	require.NoError(t, err)
+
+	n.deliver(context.Background(), n.routes[0], testMessage(model.AlertRulePriceBelow))
+
+	assert.Len(t, server.requestTimes(), 2)
+	deliveries := repo.all()
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, model.DeliveryStatusFailed, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Contains(t, deliveries[0].LastError, "500")
+	assert.Nil(t, deliveries[0].DeliveredAt)
+}
+
+func TestNotifierStopsRetryingOnShutdown(t *testing.T) {
+	server := newFlakyServer(10)
+	defer server.Close()
+
+	repo := &deliveryLog{}
+	n, err := NewNotifier(&config.NotificationConfig{Channels: []config.ChannelConfig{
+		{Name: "hook", Type: ChannelTypeWebhook, URL: server.URL, MaxAttempts: 5, Backoff: time.Hour},
+	}}, repo)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n.deliver(ctx, n.routes[0], testMessage(model.AlertRulePriceBelow))
+
+	assert.Len(t, server.requestTimes(), 1)
+	deliveries := repo.all()
+	require.Len(t, deliveries, 1, "the aborted delivery is still logged")
+	assert.Equal(t, model.DeliveryStatusFailed, deliveries[0].Status)
+}
+
+func TestNotifierFiltersAlertTypes(t *testing.T) {
+	all := newFlakyServer(0)
+	defer all.Close()
+	stock := newFlakyServer(0)
+	defer stock.Close()
+
+	repo := &deliveryLog{}
+	n, err := NewNotifier(&config.NotificationConfig{Channels: []config.ChannelConfig{
+		{Name: "all", Type: ChannelTypeWebhook, URL: all.URL},
+		{Name: "stock", Type: ChannelTypeSlack, URL: stock.URL, AlertTypes: []string{"back_in_stock", "out_of_stock"}},
+	}}, repo)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	price := testMessage(model.AlertRulePriceBelow)
+	n.Notify(price.Job, price.Event)
+	outOfStock := testMessage(model.AlertRuleOutOfStock)
+	outOfStock.Event.ID = 4
+	n.Notify(outOfStock.Job, outOfStock.Event)
+
+	require.Eventually(t, func() bool { return len(repo.all()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, all.requestTimes(), 2)
+	assert.Len(t, stock.requestTimes(), 1)
+
+	for _, delivery := range repo.all() {
+		if delivery.Channel == "stock" {
+			assert.Equal(t, uint(4), delivery.AlertEventID)
+		}
+	}
+}
+
+func TestNewNotifierRejectsInvalidChannels(t *testing.T) {
+	tests := map[string]config.ChannelConfig{
+		"missing name":       {Type: ChannelTypeWebhook, URL: "http://localhost"},
+		"unknown type":       {Name: "x", Type: "pager"},
+		"invalid alert type": {Name: "x", Type: ChannelTypeWebhook, AlertTypes: []string{"price_up"}},
+		"incomplete smtp":    {Name: "x", Type: ChannelTypeSMTP, SMTP: config.SMTPConfig{Host: "localhost"}},
+		"invalid template":   {Name: "x", Type: ChannelTypeSMTP, SMTP: config.SMTPConfig{Host: "localhost", From: "a@b.c", To: []string{"d@e.f"}, BodyTemplate: "{{ .Text"}},
+	}
+	for name, channel := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewNotifier(&config.NotificationConfig{Channels: []config.ChannelConfig{channel}}, &deliveryLog{})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// slackChannel posts the alert to an incoming webhook.
+// The payload format is understood by Slack and Mattermost.
+type slackChannel struct {
+	url    string
+	client *http.Client
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+func NewSlackChannel(url string, timeout time.Duration) *slackChannel {
+	return &slackChannel{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *slackChannel) Send(msg *Message) error {
+	body, err := json.Marshal(&slackPayload{
+		Text: "*" + msg.Subject + "*\n" + msg.Text,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doPost(c.client, req)
+}
@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+)
+
+const (
+	defaultSubjectTemplate = "{{ .Subject }}"
+	defaultBodyTemplate    = `{{ .Text }}
+
+Job:      {{ .Job.ID }}
+URL:      {{ .Job.URL }}
+Alert:    {{ .Event.Type }}
+Observed: {{ .Event.TriggeredAt.Format "2006-01-02 15:04:05 MST" }}
+`
+)
+
+// smtpChannel sends the alert as plain text email.
+// Subject and body are rendered from templates with the Message as data.
+type smtpChannel struct {
+	addr    string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	subject *template.Template
+	body    *template.Template
+}
+
+func NewSMTPChannel(cfg *config.SMTPConfig) (*smtpChannel, error) {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("smtp host, from and to are required")
+	}
+
+	subjectTmpl := cfg.SubjectTemplate
+	if subjectTmpl == "" {
+		subjectTmpl = defaultSubjectTemplate
+	}
+	subject, err := template.New("subject").Parse(subjectTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	bodyTmpl := cfg.BodyTemplate
+	if bodyTmpl == "" {
+		bodyTmpl = defaultBodyTemplate
+	}
+	body, err := template.New("body").Parse(bodyTmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 25
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &smtpChannel{
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		auth:    auth,
+		from:    cfg.From,
+		to:      cfg.To,
+		subject: subject,
+		body:    body,
+	}, nil
+}
+
+func (c *smtpChannel) Send(msg *Message) error {
+	var subject, body bytes.Buffer
+	if err := c.subject.Execute(&subject, msg); err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := c.body.Execute(&body, msg); err != nil {
+		return fmt.Errorf("failed to render body: %w", err)
+	}
+
+	var mail bytes.Buffer
+	fmt.Fprintf(&mail, "From: %s\r\n", c.from)
+	fmt.Fprintf(&mail, "To: %s\r\n", strings.Join(c.to, ", "))
+	// header values must not contain line breaks
+	fmt.Fprintf(&mail, "Subject: %s\r\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(subject.String()))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	mail.WriteString("\r\n")
+	mail.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+
+	return smtp.SendMail(c.addr, c.auth, c.from, c.to, mail.Bytes())
+}
@@ -0,0 +1,149 @@
+package notification
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mail is a message received by the SMTP stand-in.
+type mail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer runs a minimal in-process SMTP server accepting a single message.
+func startSMTPServer(t *testing.T) (host string, port int, received <-chan mail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	mails := make(chan mail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var m mail
+		reply("220 localhost ESMTP stand-in")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				m.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				m.to = append(m.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				m.data = data.String()
+				mails <- m
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, mails
+}
+
+func TestSMTPChannelRendersDefaultTemplates(t *testing.T) {
+	host, port, received := startSMTPServer(t)
+
+	channel, err := NewSMTPChannel(&config.SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "alerts@cogniprice.test",
+		To:   []string{"ops@cogniprice.test", "team@cogniprice.test"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, channel.Send(testMessage(model.AlertRulePriceBelow)))
+
+	m := <-received
+	assert.Equal(t, "alerts@cogniprice.test", m.from)
+	assert.Equal(t, []string{"ops@cogniprice.test", "team@cogniprice.test"}, m.to)
+	assert.Contains(t, m.data, "To: ops@cogniprice.test, team@cogniprice.test\r\n")
+	assert.Contains(t, m.data, "Subject: [cogniprice] price_below alert for job 7\r\n")
+	assert.Contains(t, m.data, "Content-Type: text/plain; charset=UTF-8\r\n")
+
+	body := m.data[strings.Index(m.data, "\r\n\r\n")+4:]
+	assert.Equal(t, "Price of https://shop.test/product changed from 19.99 EUR to 14.99 EUR\r\n"+
+		"\r\n"+
+		"Job:      7\r\n"+
+		"URL:      https://shop.test/product\r\n"+
+		"Alert:    price_below\r\n"+
+		"Observed: 2026-03-01 12:30:00 UTC\r\n", body)
+}
+
+func TestSMTPChannelRendersCustomTemplates(t *testing.T) {
+	host, port, received := startSMTPServer(t)
+
+	channel, err := NewSMTPChannel(&config.SMTPConfig{
+		Host:            host,
+		Port:            port,
+		From:            "alerts@cogniprice.test",
+		To:              []string{"ops@cogniprice.test"},
+		SubjectTemplate: "Alert {{ .Event.ID }}\non {{ .Job.URL }}",
+		BodyTemplate:    "{{ .Event.Type }}: {{ .Event.PreviousAmount }} -> {{ .Event.Amount }} {{ .Event.Currency }}\n",
+	})
+	require.NoError(t, err)
+	require.NoError(t, channel.Send(testMessage(model.AlertRulePriceBelow)))
+
+	m := <-received
+	assert.Contains(t, m.data, "Subject: Alert 3 on https://shop.test/product\r\n", "line breaks are removed from the subject")
+	assert.True(t, strings.HasSuffix(m.data, "\r\n\r\nprice_below: 1999 -> 1499 EUR\r\n"), m.data)
+}
+
+func TestSMTPChannelFailsOnTemplateError(t *testing.T) {
+	channel, err := NewSMTPChannel(&config.SMTPConfig{
+		Host:         "127.0.0.1",
+		Port:         1,
+		From:         "alerts@cogniprice.test",
+		To:           []string{"ops@cogniprice.test"},
+		BodyTemplate: "{{ .Job.Missing }}",
+	})
+	require.NoError(t, err)
+
+	err = channel.Send(testMessage(model.AlertRulePriceBelow))
+
+	assert.ErrorContains(t, err, "failed to render body")
+}
@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+const (
+	SignatureHeader = "X-Cogniprice-Signature"
+	TimestampHeader = "X-Cogniprice-Timestamp"
+)
+
+// webhookChannel posts the alert as JSON to a generic HTTP endpoint.
+// If a secret is configured, the payload is signed with HMAC-SHA256.
+type webhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+type webhookPayload struct {
+	Text  string                    `json:"text"`
+	Job   *model.JobResponse        `json:"job"`
+	Alert *model.AlertEventResponse `json:"alert"`
+}
+
+func NewWebhookChannel(url, secret string, timeout time.Duration) *webhookChannel {
+	return &webhookChannel{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *webhookChannel) Send(msg *Message) error {
+	body, err := json.Marshal(&webhookPayload{
+		Text:  msg.Text,
+		Job:   model.ToJobResponse(msg.Job),
+		Alert: model.ToAlertEventResponse(msg.Event),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(c.secret, timestamp, body))
+	}
+
+	return doPost(c.client, req)
+}
+
+// Sign returns the signature of a payload sent at timestamp (unix seconds).
+// The signature is the hex encoded HMAC-SHA256 of "<timestamp>.<body>", prefixed with "sha256=".
+// Receivers should recompute it and reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// doPost sends the request and treats any non-2xx response as an error.
+func doPost(client *http.Client, req *http.Request) error {
+	_, err := post(client, req)
+	return err
+}
+
+// post sends the request and returns the status code of the response,
+// zero if none was received. Any non-2xx response is an error.
+func post(client *http.Client, req *http.Request) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"text":"hello"}`)
+
+	signature := Sign("secret", 1700000000, body)
+
+	// HMAC-SHA256 of `1700000000.{"text":"hello"}` with key "secret"
+	assert.Equal(t, "sha256=1898b1f7ee8ff2fe446237422bd9b3afcdb1fff758351d6ee4236bc6f1530852", signature)
+	assert.NotEqual(t, signature, Sign("other", 1700000000, body), "depends on the secret")
+	assert.NotEqual(t, signature, Sign("secret", 1700000001, body), "depends on the timestamp")
+	assert.NotEqual(t, signature, Sign("secret", 1700000000, []byte(`{"text":"hellO"}`)), "depends on the body")
+}
+
+func TestWebhookChannelSignsPayload(t *testing.T) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	channel := NewWebhookChannel(server.URL, "s3cret", time.Second)
+	require.NoError(t, channel.Send(testMessage(model.AlertRulePriceBelow)))
+
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+	assert.Equal(t, Sign("s3cret", timestamp, body), header.Get(SignatureHeader))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	var payload struct {
+		Text  string `json:"text"`
+		Job   struct{ ID uint }
+		Alert struct{ Type string }
+	}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "Price of https://shop.test/product changed from 19.99 EUR to 14.99 EUR", payload.Text)
+	assert.Equal(t, uint(7), payload.Job.ID)
+	assert.Equal(t, "price_below", payload.Alert.Type)
+}
+
+func TestWebhookChannelWithoutSecretIsUnsigned(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewWebhookChannel(server.URL, "", time.Second).Send(testMessage(model.AlertRulePriceBelow)))
+
+	assert.Empty(t, header.Get(SignatureHeader))
+	assert.Empty(t, header.Get(TimestampHeader))
+}
+
+func TestWebhookChannelFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhookChannel(server.URL, "s3cret", time.Second).Send(testMessage(model.AlertRulePriceBelow))
+
+	assert.ErrorContains(t, err, "502")
+}
@@ -0,0 +1,161 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval limits how often the key set is fetched, so tokens with made up
+// key IDs or an unreachable identity provider do not cause a fetch for every request.
+const minRefreshInterval = 30 * time.Second
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// keySet caches the signing keys of the identity provider. The keys are fetched again
+// once they expire, or earlier if a token is signed by an unknown key, e.g. after a rotation.
+// If fetching fails, the cached keys are used until the next attempt.
+// Concurrent requests share a single fetch, the lock is not held while fetching.
+type keySet struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+	fetch  singleflight.Group
+	// refreshInterval is the minimum time between two fetches
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*jose.JSONWebKey
+	fetchedAt time.Time
+	triedAt   time.Time
+}
+
+func newKeySet(url string, ttl, timeout time.Duration) *keySet {
+	return &keySet{
+		url:             url,
+		ttl:             ttl,
+		client:          &http.Client{Timeout: timeout},
+		refreshInterval: minRefreshInterval,
+	}
+}
+
+// key returns the key with the ID kid. An empty kid matches the only key of a set with a single key.
+// Returns errUnknownKey if there is no such key, and the error of the fetch if no keys were fetched yet.
+func (s *keySet) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	s.mu.Lock()
+	key, ok := s.lookup(kid)
+	expired := time.Since(s.fetchedAt) >= s.ttl
+	s.mu.Unlock()
+	if ok && !expired {
+		return key, nil
+	}
+
+	// the fetch is shared by the waiting requests, so it is not canceled with the request starting it
+	_, err, _ := s.fetch.Do(s.url, func() (any, error) {
+		return nil, s.refresh(context.WithoutCancel(ctx))
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		if err == nil {
+			err = errors.New("jwks not fetched yet")
+		}
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("[WARN] failed to refresh jwks, using cached keys: %v\n", err)
+	}
+
+	key, ok = s.lookup(kid)
+	if !ok {
+		return nil, errUnknownKey
+	}
+	return key, nil
+}
+
+// refresh fetches the keys, unless they were fetched less than refreshInterval ago.
+func (s *keySet) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	if time.Since(s.triedAt) < s.refreshInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.triedAt = time.Now()
+	s.mu.Unlock()
+
+	keys, err := s.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+// lookup returns the cached key with the ID kid. The caller must hold the lock.
+func (s *keySet) lookup(kid string) (*jose.JSONWebKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// get fetches the signing keys of the key set by their IDs.
+func (s *keySet) get(ctx context.Context) (map[string]*jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: unexpected response status: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]*jose.JSONWebKey, len(set.Keys))
+	for _, raw := range set.Keys {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(raw); err != nil {
+			// keys of unsupported types are skipped, tokens signed by them are rejected
+			log.Printf("[WARN] skipping jwk: %v\n", err)
+			continue
+		}
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		// symmetric keys have no public key and are skipped
+		public := key.Public()
+		if !public.Valid() {
+			continue
+		}
+		keys[key.KeyID] = &public
+	}
+	return keys, nil
+}
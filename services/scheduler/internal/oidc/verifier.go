@@ -0,0 +1,234 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+)
+
+const (
+	defaultSubjectClaim = "sub"
+	defaultRoleClaim    = "roles"
+	defaultCacheTTL     = time.Hour
+	defaultLeeway       = time.Minute
+	defaultTimeout      = 10 * time.Second
+)
+
+// signatureAlgorithms are the algorithms tokens may be signed with, others (e.g. "none") are rejected.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.ES256, jose.ES384, jose.ES512,
+}
+
+// rolePrecedence decides the role of a token whose claims map to several roles.
+var rolePrecedence = []model.Role{model.RoleAdmin, model.RoleOperator, model.RoleReadOnly, model.RoleWorker}
+
+var errUnavailable = &service.AppError{
+	Message: "the signing keys of the identity provider are unavailable",
+	Code:    "AUTH_UNAVAILABLE",
+	Status:  503,
+}
+
+// Verifier authenticates the JWT bearer tokens issued by an OIDC identity provider.
+// Tokens must be signed by a key of the provider's JWKS, expire, and match the configured issuer and audience.
+type Verifier struct {
+	issuer       string
+	audience     string
+	subjectClaim string
+	roleClaim    string
+	tenantClaim  string
+	// roles maps the values of the role claim to roles
+	roles  map[string]model.Role
+	leeway time.Duration
+	keys   *keySet
+}
+
+func NewVerifier(cfg *config.OIDCConfig) (*Verifier, error) {
+	if cfg.JWKSURL == "" {
+		return nil, errors.New("oidc: jwks_url is required")
+	}
+	if cfg.Issuer == "" {
+		return nil, errors.New("oidc: issuer is required")
+	}
+	if cfg.Audience == "" {
+		return nil, errors.New("oidc: audience is required")
+	}
+
+	roles := map[string]model.Role{}
+	for name, values := range cfg.Roles {
+		role := model.Role(name)
+		if !role.IsValid() {
+			return nil, fmt.Errorf("oidc: invalid role %q, expected admin, operator, read_only or worker", name)
+		}
+		for _, value := range values {
+			roles[value] = role
+		}
+	}
+
+	v := &Verifier{
+		issuer:       cfg.Issuer,
+		audience:     cfg.Audience,
+		subjectClaim: cfg.SubjectClaim,
+		roleClaim:    cfg.RoleClaim,
+		tenantClaim:  cfg.TenantClaim,
+		roles:        roles,
+		leeway:       cfg.Leeway,
+	}
+	if v.subjectClaim == "" {
+		v.subjectClaim = defaultSubjectClaim
+	}
+	if v.roleClaim == "" {
+		v.roleClaim = defaultRoleClaim
+	}
+	if v.leeway <= 0 {
+		v.leeway = defaultLeeway
+	}
+
+	ttl := cfg.JWKSCacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	v.keys = newKeySet(cfg.JWKSURL, ttl, timeout)
+
+	return v, nil
+}
+
+// Authenticate verifies a bearer token and returns its principal, named by the subject claim.
+// Returns service.ErrUnauthorized if the token is invalid, and service.ErrForbidden if its
+// claims map to no role or name no valid tenant.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (*model.Principal, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		var appErr *service.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		log.Printf("[WARN] rejected bearer token: %v\n", err)
+		return nil, service.ErrUnauthorized
+	}
+
+	subject, _ := lookupClaim(claims, v.subjectClaim).(string)
+	if subject == "" {
+		log.Printf("[WARN] rejected bearer token: missing claim %q\n", v.subjectClaim)
+		return nil, service.ErrUnauthorized
+	}
+
+	role, ok := v.role(claims)
+	if !ok {
+		log.Printf("[WARN] bearer token of %q has no role\n", subject)
+		return nil, service.ErrForbidden
+	}
+
+	tenant := model.DefaultTenant
+	if v.tenantClaim != "" {
+		tenant, _ = lookupClaim(claims, v.tenantClaim).(string)
+		if !model.IsValidTenant(tenant) {
+			log.Printf("[WARN] bearer token of %q has no valid tenant in claim %q\n", subject, v.tenantClaim)
+			return nil, service.ErrForbidden
+		}
+	}
+
+	return &model.Principal{Subject: subject, Role: role, Tenant: tenant}, nil
+}
+
+// verify checks the signature and the registered claims of a token and returns its claims.
+func (v *Verifier) verify(ctx context.Context, token string) (map[string]any, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	header := parsed.Headers[0]
+
+	key, err := v.keys.key(ctx, header.KeyID)
+	if err != nil {
+		if errors.Is(err, errUnknownKey) {
+			return nil, fmt.Errorf("%w %q", err, header.KeyID)
+		}
+		log.Printf("[ERROR] %v\n", err)
+		return nil, errUnavailable
+	}
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		return nil, fmt.Errorf("algorithm %s does not match key %q", header.Algorithm, header.KeyID)
+	}
+
+	var registered jwt.Claims
+	var claims map[string]any
+	if err := parsed.Claims(key.Key, &registered, &claims); err != nil {
+		return nil, err
+	}
+
+	if registered.Expiry == nil {
+		return nil, errors.New("missing claim exp")
+	}
+	expected := jwt.Expected{
+		Issuer:      v.issuer,
+		AnyAudience: jwt.Audience{v.audience},
+		Time:        time.Now(),
+	}
+	if err := registered.ValidateWithLeeway(expected, v.leeway); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// role returns the role with the highest precedence the role claim maps to.
+func (v *Verifier) role(claims map[string]any) (model.Role, bool) {
+	var granted []model.Role
+	for _, value := range claimValues(lookupClaim(claims, v.roleClaim)) {
+		if role, ok := v.roles[value]; ok {
+			granted = append(granted, role)
+		}
+	}
+
+	for _, role := range rolePrecedence {
+		if slices.Contains(granted, role) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// lookupClaim returns the claim at path, nested claims are separated by dots, e.g. "realm_access.roles".
+func lookupClaim(claims map[string]any, path string) any {
+	var value any = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// claimValues returns the strings of a claim, which is either an array of strings
+// or a single string of space separated values like the scope claim.
+func claimValues(claim any) []string {
+	switch c := claim.(type) {
+	case string:
+		return strings.Fields(c)
+	case []any:
+		values := make([]string, 0, len(c))
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.test/realms/cogniprice"
+	testAudience = "cogniprice-scheduler"
+)
+
+var (
+	rsaKey   = mustRSAKey()
+	otherKey = mustRSAKey()
+	ecKey    = mustECKey()
+)
+
+func mustRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustECKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// jwksServer serves the public keys of a key set and counts the requests.
+type jwksServer struct {
+	*httptest.Server
+	requests atomic.Int32
+
+	mu   sync.Mutex
+	keys []jose.JSONWebKey
+}
+
+func newJWKSServer(t *testing.T, keys ...jose.JSONWebKey) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: s.keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...jose.JSONWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func publicJWK(key any, kid, alg string) jose.JSONWebKey {
+	jwk := jose.JSONWebKey{Key: key, KeyID: kid, Algorithm: alg, Use: "sig"}
+	return jwk.Public()
+}
+
+func newTestVerifier(t *testing.T, jwksURL string) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(&config.OIDCConfig{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSURL:  jwksURL,
+		Timeout:  time.Second,
+		Roles:    map[string][]string{"admin": {"admins"}, "read_only": {"viewers"}},
+	})
+	require.NoError(t, err)
+	return v
+}
+
+// validClaims returns the claims of a valid token of an admin.
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"sub":   "alice",
+		"iss":   testIssuer,
+		"aud":   []string{testAudience, "account"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"roles": []string{"admins"},
+	}
+}
+
+func sign(t *testing.T, alg jose.SignatureAlgorithm, key any, kid string, claims map[string]any) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func with(claims map[string]any, name string, value any) map[string]any {
+	claims[name] = value
+	return claims
+}
+
+func without(claims map[string]any, name string) map[string]any {
+	delete(claims, name)
+	return claims
+}
+
+// unsigned returns a token with the algorithm none.
+func unsigned(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "none", "typ": "JWT", "kid": "rsa"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestAuthenticate(t *testing.T) {
+	jwks := newJWKSServer(t,
+		publicJWK(&rsaKey.PublicKey, "rsa", ""),
+		publicJWK(&ecKey.PublicKey, "ec", ""),
+		publicJWK(&otherKey.PublicKey, "rs384-only", "RS384"),
+	)
+	v := newTestVerifier(t, jwks.URL)
+
+	tests := []struct {
+		name  string
+		token string
+		want  *model.Principal
+		err   error
+	}{
+		{
+			name:  "valid RS256 token",
+			token: sign(t, jose.RS256, rsaKey, "rsa", validClaims()),
+			want:  &model.Principal{Subject: "alice", Role: model.RoleAdmin, Tenant: model.DefaultTenant},
+		},
+		{
+			name:  "valid ES256 token",
+			token: sign(t, jose.ES256, ecKey, "ec", with(validClaims(), "roles", "viewers")),
+			want:  &model.Principal{Subject: "alice", Role: model.RoleReadOnly, Tenant: model.DefaultTenant},
+		},
+		{
+			name:  "bad signature",
+			token: sign(t, jose.RS256, otherKey, "rsa", validClaims()),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "algorithm of another key type",
+			token: sign(t, jose.ES256, ecKey, "rsa", validClaims()),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "algorithm not allowed for the key",
+			token: sign(t, jose.RS256, otherKey, "rs384-only", validClaims()),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "HMAC signed with the public key",
+			token: sign(t, jose.HS256, rsaKey.PublicKey.N.Bytes(), "rsa", validClaims()),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "alg none",
+			token: unsigned(t, validClaims()),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "expired",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "exp", time.Now().Add(-time.Hour).Unix())),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "expired within the leeway",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "exp", time.Now().Add(-30*time.Second).Unix())),
+			want:  &model.Principal{Subject: "alice", Role: model.RoleAdmin, Tenant: model.DefaultTenant},
+		},
+		{
+			name:  "without exp",
+			token: sign(t, jose.RS256, rsaKey, "rsa", without(validClaims(), "exp")),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "not valid yet",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "nbf", time.Now().Add(time.Hour).Unix())),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "wrong issuer",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "iss", "https://evil.test")),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "wrong audience",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "aud", "other-service")),
+			err:   service.ErrUnauthorized,
+		},
+		{
+			name:  "without role",
+			token: sign(t, jose.RS256, rsaKey, "rsa", with(validClaims(), "roles", []string{"unknown"})),
+			err:   service.ErrForbidden,
+		},
+		{
+			name:  "malformed",
+			token: "not.a.token",
+			err:   service.ErrUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := v.Authenticate(t.Context(), tt.token)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, principal)
+		})
+	}
+	assert.EqualValues(t, 1, jwks.requests.Load(), "the keys are cached")
+}
+
+func TestAuthenticateRefetchesUnknownKey(t *testing.T) {
+	jwks := newJWKSServer(t, publicJWK(&rsaKey.PublicKey, "rsa", ""))
+	v := newTestVerifier(t, jwks.URL)
+
+	_, err := v.Authenticate(t.Context(), sign(t, jose.RS256, rsaKey, "rsa", validClaims()))
+	require.NoError(t, err)
+
+	// unknown keys are not fetched again within the refresh interval
+	rotated := sign(t, jose.RS256, otherKey, "rotated", validClaims())
+	jwks.setKeys(publicJWK(&rsaKey.PublicKey, "rsa", ""), publicJWK(&otherKey.PublicKey, "rotated", ""))
+	_, err = v.Authenticate(t.Context(), rotated)
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+	assert.EqualValues(t, 1, jwks.requests.Load())
+
+	// afterwards the key set is fetched again for the unknown key
+	v.keys.refreshInterval = 0
+	principal, err := v.Authenticate(t.Context(), rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.EqualValues(t, 2, jwks.requests.Load())
+
+	_, err = v.Authenticate(t.Context(), sign(t, jose.RS256, otherKey, "made-up", validClaims()))
+	assert.ErrorIs(t, err, service.ErrUnauthorized)
+	assert.EqualValues(t, 3, jwks.requests.Load())
+}
+
+func TestAuthenticateSharesFetch(t *testing.T) {
+	release := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{publicJWK(&rsaKey.PublicKey, "rsa", "")}})
+	}))
+	t.Cleanup(server.Close)
+	v := newTestVerifier(t, server.URL)
+	token := sign(t, jose.RS256, rsaKey, "rsa", validClaims())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Authenticate(t.Context(), token)
+			errs <- err
+		}()
+	}
+
+	// the lock is not held while fetching, so other keys can be looked up meanwhile
+	assert.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+	v.keys.mu.Lock()
+	_, ok := v.keys.lookup("rsa")
+	v.keys.mu.Unlock()
+	assert.False(t, ok)
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, requests.Load())
+}
+
+func TestAuthenticateUnavailableKeys(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	v := newTestVerifier(t, url)
+	_, err := v.Authenticate(t.Context(), sign(t, jose.RS256, rsaKey, "rsa", validClaims()))
+
+	var appErr *service.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, 503, appErr.Status)
+}
+
+func TestAuthenticateUsesCachedKeysIfFetchFails(t *testing.T) {
+	jwks := newJWKSServer(t, publicJWK(&rsaKey.PublicKey, "rsa", ""))
+	v := newTestVerifier(t, jwks.URL)
+	token := sign(t, jose.RS256, rsaKey, "rsa", validClaims())
+
+	_, err := v.Authenticate(t.Context(), token)
+	require.NoError(t, err)
+
+	// the keys expired, but the identity provider is unreachable
+	jwks.Close()
+	v.keys.ttl = 0
+	v.keys.refreshInterval = 0
+	_, err = v.Authenticate(t.Context(), token)
+	assert.NoError(t, err)
+}
+
+func TestNewVerifier(t *testing.T) {
+	valid := config.OIDCConfig{Issuer: testIssuer, Audience: testAudience, JWKSURL: "https://idp.test/certs"}
+
+	tests := map[string]func(cfg *config.OIDCConfig){
+		"missing jwks_url": func(cfg *config.OIDCConfig) { cfg.JWKSURL = "" },
+		"missing issuer":   func(cfg *config.OIDCConfig) { cfg.Issuer = "" },
+		"missing audience": func(cfg *config.OIDCConfig) { cfg.Audience = "" },
+		"invalid role":     func(cfg *config.OIDCConfig) { cfg.Roles = map[string][]string{"owner": {"owners"}} },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid
+			modify(&cfg)
+			_, err := NewVerifier(&cfg)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewVerifier(&valid)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,366 @@
+// Package parquet writes Parquet files row group by row group, so large files are
+// streamed without holding all rows in memory. Only flat schemas of required and
+// optional columns are supported, values are stored plain encoded and uncompressed.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	// String columns hold UTF-8 strings.
+	String Type = iota
+	// Int64 columns hold 64 bit integers.
+	Int64
+	// Bool columns hold booleans.
+	Bool
+	// Timestamp columns hold times, stored as microseconds since the Unix epoch in UTC.
+	Timestamp
+)
+
+// Column describes a column of a file.
+type Column struct {
+	Name string
+	Type Type
+	// Optional columns accept nil values.
+	Optional bool
+}
+
+// Values of the Parquet format, see https://github.com/apache/parquet-format.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+const createdBy = "cogniprice scheduler"
+
+var magic = []byte("PAR1")
+
+// ErrClosed is returned when writing to a closed Writer.
+var ErrClosed = errors.New("parquet: writer is closed")
+
+// Writer writes rows to a Parquet file. The rows are buffered and written
+// as a row group every rowGroupSize rows, the file is completed by Close.
+type Writer struct {
+	w            io.Writer
+	columns      []Column
+	rowGroupSize int
+
+	// bytes written to w
+	offset    int64
+	started   bool
+	closed    bool
+	rows      [][]any
+	numRows   int64
+	rowGroups []rowGroup
+}
+
+type rowGroup struct {
+	numRows int64
+	size    int64
+	chunks  []columnChunk
+}
+
+// columnChunk is the location of the values of a column in a row group, stored as a single page.
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// NewWriter returns a Writer writing a file with the columns to w.
+func NewWriter(w io.Writer, columns []Column, rowGroupSize int) *Writer {
+	return &Writer{
+		w:            w,
+		columns:      columns,
+		rowGroupSize: max(rowGroupSize, 1),
+	}
+}
+
+// Write adds a row, values[i] being the value of columns[i]: a string, int64, bool or
+// time.Time depending on the type of the column, or nil if the column is optional.
+// The buffered rows are written as a row group once there are rowGroupSize rows.
+func (w *Writer) Write(values []any) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("parquet: expected %d values, got %d", len(w.columns), len(values))
+	}
+	for i, column := range w.columns {
+		if err := column.check(values[i]); err != nil {
+			return err
+		}
+	}
+
+	w.rows = append(w.rows, values)
+	if len(w.rows) >= w.rowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	group := rowGroup{numRows: int64(len(w.rows))}
+	for i, column := range w.columns {
+		page := column.page(w.rows, i)
+		chunk := columnChunk{offset: w.offset, size: int64(len(page)), numValues: int64(len(w.rows))}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.numRows
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// Close writes the buffered rows and the footer of the file. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	footer := w.footer()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return w.write(append(footer, magic...))
+}
+
+// start writes the magic number the file starts with.
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write(magic)
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// footer returns the FileMetaData of the file.
+func (w *Writer) footer() []byte {
+	t := &thriftWriter{}
+	t.beginStruct()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(w.columns)+1)
+	t.beginStruct()
+	t.string(4, "schema")
+	t.i32(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, column := range w.columns {
+		column.schemaElement(t)
+	}
+
+	t.i64(3, w.numRows)
+
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.beginStruct()
+		t.list(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			w.columns[i].columnChunk(t, chunk)
+		}
+		t.i64(2, group.size)
+		t.i64(3, group.numRows)
+		t.endStruct()
+	}
+
+	t.string(6, createdBy)
+	t.endStruct()
+	return t.buf.Bytes()
+}
+
+// check returns an error if v is no valid value of the column.
+func (c Column) check(v any) error {
+	if v == nil {
+		if !c.Optional {
+			return fmt.Errorf("parquet: column %s is required", c.Name)
+		}
+		return nil
+	}
+
+	var ok bool
+	switch c.Type {
+	case String:
+		_, ok = v.(string)
+	case Int64:
+		_, ok = v.(int64)
+	case Bool:
+		_, ok = v.(bool)
+	case Timestamp:
+		_, ok = v.(time.Time)
+	}
+	if !ok {
+		return fmt.Errorf("parquet: invalid value %T of column %s", v, c.Name)
+	}
+	return nil
+}
+
+// page returns the data page with the values of the column in rows, at index i of the rows.
+// Optional columns start with the definition levels, 0 for nil and 1 for other values.
+func (c Column) page(rows [][]any, i int) []byte {
+	var values bytes.Buffer
+	var defined, bools []bool
+	for _, row := range rows {
+		v := row[i]
+		if c.Optional {
+			defined = append(defined, v != nil)
+		}
+		if v == nil {
+			continue
+		}
+
+		switch c.Type {
+		case String:
+			s := v.(string)
+			values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+			values.WriteString(s)
+		case Int64:
+			values.Write(binary.LittleEndian.AppendUint64(nil, uint64(v.(int64))))
+		case Bool:
+			bools = append(bools, v.(bool))
+		case Timestamp:
+			values.Write(binary.LittleEndian.AppendUint64(nil, uint64(v.(time.Time).UnixMicro())))
+		}
+	}
+	if c.Type == Bool {
+		values.Write(packBits(bools))
+	}
+
+	var data []byte
+	if c.Optional {
+		levels := encodeLevels(defined)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(levels)))
+		data = append(data, levels...)
+	}
+	data = append(data, values.Bytes()...)
+
+	t := &thriftWriter{}
+	t.beginStruct()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(len(data)))
+	t.i32(3, int32(len(data)))
+	t.structField(5)
+	t.i32(1, int32(len(rows)))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.endStruct()
+	t.endStruct()
+	return append(t.buf.Bytes(), data...)
+}
+
+// schemaElement writes the SchemaElement of the column.
+func (c Column) schemaElement(t *thriftWriter) {
+	t.beginStruct()
+	t.i32(1, c.physicalType())
+	repetition := int32(repetitionRequired)
+	if c.Optional {
+		repetition = repetitionOptional
+	}
+	t.i32(3, repetition)
+	t.string(4, c.Name)
+	switch c.Type {
+	case String:
+		t.i32(6, convertedUTF8)
+	case Timestamp:
+		t.i32(6, convertedTimestampMicros)
+	}
+	t.endStruct()
+}
+
+// columnChunk writes the ColumnChunk of the column with its ColumnMetaData.
+func (c Column) columnChunk(t *thriftWriter, chunk columnChunk) {
+	t.beginStruct()
+	t.i64(2, chunk.offset)
+	t.structField(3)
+	t.i32(1, c.physicalType())
+	t.list(2, thriftI32, 2)
+	t.varint(encodingPlain)
+	t.varint(encodingRLE)
+	t.list(3, thriftBinary, 1)
+	t.binary(c.Name)
+	t.i32(4, codecUncompressed)
+	t.i64(5, chunk.numValues)
+	t.i64(6, chunk.size)
+	t.i64(7, chunk.size)
+	t.i64(9, chunk.offset)
+	t.endStruct()
+	t.endStruct()
+}
+
+func (c Column) physicalType() int32 {
+	switch c.Type {
+	case Bool:
+		return typeBoolean
+	case Int64, Timestamp:
+		return typeInt64
+	default:
+		return typeByteArray
+	}
+}
+
+// encodeLevels encodes levels of bit width 1 as a single bit-packed run of the RLE/bit-packing hybrid encoding.
+func encodeLevels(levels []bool) []byte {
+	packed := packBits(levels)
+	header := binary.AppendUvarint(nil, uint64(len(packed))<<1|1)
+	return append(header, packed...)
+}
+
+// packBits packs the values into bytes, least significant bit first.
+func packBits(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
@@ -0,0 +1,247 @@
+package parquet_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/parquet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var columns = []parquet.Column{
+	{Name: "id", Type: parquet.Int64},
+	{Name: "url", Type: parquet.String},
+	{Name: "adaptive", Type: parquet.Bool},
+	{Name: "deletedAt", Type: parquet.Timestamp, Optional: true},
+	{Name: "minInterval", Type: parquet.String, Optional: true},
+}
+
+func TestWriter(t *testing.T) {
+	deletedAt := time.Date(2026, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	rows := [][]any{
+		{int64(1), "https://shop.test/a", true, nil, "1h"},
+		{int64(2), "https://shop.test/b", false, deletedAt, nil},
+		{int64(-3), "", true, nil, nil},
+	}
+
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, columns, 2)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+
+	file := buf.Bytes()
+	meta := readFooter(t, file)
+	assert.EqualValues(t, 1, meta[1])
+	assert.EqualValues(t, 3, meta[3])
+
+	schema := meta[2].([]any)
+	require.Len(t, schema, len(columns)+1)
+	assert.EqualValues(t, len(columns), field(schema[0], 5))
+	for i, column := range columns {
+		element := schema[i+1]
+		assert.Equal(t, column.Name, field(element, 4))
+		repetition := int64(0)
+		if column.Optional {
+			repetition = 1
+		}
+		assert.Equal(t, repetition, field(element, 3), column.Name)
+	}
+
+	// two rows fill the first row group, the last row is written by Close
+	groups := meta[4].([]any)
+	require.Len(t, groups, 2)
+	assert.EqualValues(t, 2, field(groups[0], 3))
+	assert.EqualValues(t, 1, field(groups[1], 3))
+
+	var read [][]any
+	for _, group := range groups {
+		numRows := int(field(group, 3).(int64))
+		groupRows := make([][]any, numRows)
+		for i := range groupRows {
+			groupRows[i] = make([]any, len(columns))
+		}
+		for i, chunk := range field(group, 1).([]any) {
+			meta := field(chunk, 3)
+			assert.Equal(t, []any{columns[i].Name}, field(meta, 3))
+			assert.EqualValues(t, numRows, field(meta, 5))
+
+			values := readPage(t, file[field(meta, 9).(int64):], columns[i], numRows)
+			for j, v := range values {
+				groupRows[j][i] = v
+			}
+		}
+		read = append(read, groupRows...)
+	}
+	assert.Equal(t, rows, read)
+}
+
+func TestWriterEmptyFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := parquet.NewWriter(&buf, columns, 100)
+	require.NoError(t, w.Close())
+
+	meta := readFooter(t, buf.Bytes())
+	assert.EqualValues(t, 0, meta[3])
+	assert.Empty(t, meta[4])
+	assert.ErrorIs(t, w.Write([]any{int64(1), "", false, nil, nil}), parquet.ErrClosed)
+}
+
+func TestWriterRejectsInvalidRows(t *testing.T) {
+	w := parquet.NewWriter(&bytes.Buffer{}, columns, 100)
+
+	tests := map[string][]any{
+		"missing values":      {int64(1), "https://shop.test/a"},
+		"nil required value":  {nil, "https://shop.test/a", true, nil, nil},
+		"wrong type":          {1, "https://shop.test/a", true, nil, nil},
+		"wrong optional type": {int64(1), "https://shop.test/a", true, "2026-03-01", nil},
+	}
+	for name, row := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, w.Write(row))
+		})
+	}
+}
+
+// readFooter checks the magic numbers of the file and returns its FileMetaData.
+func readFooter(t *testing.T, file []byte) map[int16]any {
+	t.Helper()
+	require.GreaterOrEqual(t, len(file), 12)
+	require.Equal(t, "PAR1", string(file[:4]))
+	require.Equal(t, "PAR1", string(file[len(file)-4:]))
+
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	r := &thriftReader{b: file[len(file)-8-size : len(file)-8]}
+	meta := r.structValue()
+	require.Equal(t, size, r.pos)
+	return meta
+}
+
+// readPage decodes the values of a plain encoded data page of the column.
+func readPage(t *testing.T, b []byte, column parquet.Column, numRows int) []any {
+	t.Helper()
+	r := &thriftReader{b: b}
+	header := r.structValue()
+	assert.EqualValues(t, 0, header[1])
+	assert.EqualValues(t, numRows, field(header[5], 1))
+	data := b[r.pos : r.pos+int(header[2].(int64))]
+
+	defined := make([]bool, numRows)
+	for i := range defined {
+		defined[i] = true
+	}
+	if column.Optional {
+		size := int(binary.LittleEndian.Uint32(data))
+		levels := &thriftReader{b: data[4 : 4+size]}
+		runHeader := levels.uvarint()
+		require.EqualValues(t, 1, runHeader&1, "expected a bit-packed run")
+		packed := levels.b[levels.pos:]
+		for i := range defined {
+			defined[i] = packed[i/8]&(1<<(i%8)) != 0
+		}
+		data = data[4+size:]
+	}
+
+	values := make([]any, numRows)
+	bit := 0
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch column.Type {
+		case parquet.String:
+			n := int(binary.LittleEndian.Uint32(data))
+			values[i] = string(data[4 : 4+n])
+			data = data[4+n:]
+		case parquet.Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(data))
+			data = data[8:]
+		case parquet.Timestamp:
+			values[i] = time.UnixMicro(int64(binary.LittleEndian.Uint64(data))).UTC()
+			data = data[8:]
+		case parquet.Bool:
+			values[i] = data[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		}
+	}
+	return values
+}
+
+func field(v any, id int16) any {
+	return v.(map[int16]any)[id]
+}
+
+// thriftReader decodes the thrift compact protocol. Structs are decoded to maps by field ID,
+// lists to slices, integers to int64 and binaries to strings.
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) structValue() map[int16]any {
+	fields := map[int16]any{}
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 4, 5, 6:
+		return r.varint()
+	case 8:
+		n := int(r.uvarint())
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		header := r.byte()
+		n := int(header >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case 12:
+		return r.structValue()
+	}
+	panic("unsupported thrift type")
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.b[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() int64 {
+	u := r.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
@@ -1,7 +1,28 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("duplicate")
+	ErrConflict  = errors.New("conflict")
 )
+
+// ConflictError is returned if jobs were modified concurrently, i.e. their
+// stored version no longer matches the version they were read with.
+// It matches ErrConflict with errors.Is.
+type ConflictError struct {
+	// IDs of the jobs that were not saved
+	IDs []uint
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("jobs %v were modified concurrently", e.IDs)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
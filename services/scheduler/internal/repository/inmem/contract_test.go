@@ -0,0 +1,14 @@
+package inmem_test
+
+import (
+	"testing"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/inmem"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/repotest"
+)
+
+func TestJobRepositoryContract(t *testing.T) {
+	repotest.RunJobRepositoryContract(t, func(t *testing.T) repotest.JobRepository {
+		return inmem.New()
+	})
+}
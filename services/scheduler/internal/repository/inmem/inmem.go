@@ -1,56 +1,426 @@
 package inmem
 
 import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
 )
 
+// inmemJobRepository is an in-memory job repository with the same semantics as
+// the postgres implementation. Jobs are copied on read and write, so callers
+// never share state with the repository.
 type inmemJobRepository struct {
-	data map[uint]*model.Job
-	mu   sync.RWMutex
+	data   map[uint]*model.Job
+	nextID uint
+	mu     sync.RWMutex
 }
 
 func New() *inmemJobRepository {
 	return &inmemJobRepository{
-		data: map[uint]*model.Job{},
+		data:   map[uint]*model.Job{},
+		nextID: 1,
 	}
 }
 
-func (r *inmemJobRepository) GetJob(id uint) (*model.Job, error) {
+func (r *inmemJobRepository) GetByID(ctx context.Context, id int) (*model.Job, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	v, ok := r.data[id]
-	if !ok {
+	if err := checkScope(ctx); err != nil {
+		return nil, err
+	}
+	v, ok := r.data[uint(id)]
+	if !ok || v.IsDeleted() || !inTenant(ctx, v) {
 		return nil, repository.ErrNotFound
 	}
-	return v, nil
+	return clone(v), nil
+}
+
+func (r *inmemJobRepository) GetDeleted(ctx context.Context, id int) (*model.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return nil, err
+	}
+	v, ok := r.data[uint(id)]
+	if !ok || !v.IsDeleted() || !inTenant(ctx, v) {
+		return nil, repository.ErrNotFound
+	}
+	return clone(v), nil
+}
+
+func (r *inmemJobRepository) GetByURL(ctx context.Context, url string) (*model.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return nil, err
+	}
+	for _, job := range r.data {
+		if job.URL == url && !job.IsDeleted() && inTenant(ctx, job) {
+			return clone(job), nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// TakenURLs returns the URLs of urls used by a job, soft deleted jobs are ignored.
+func (r *inmemJobRepository) TakenURLs(ctx context.Context, urls []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return nil, err
+	}
+
+	used := map[string]bool{}
+	for _, job := range r.data {
+		if !job.IsDeleted() && inTenant(ctx, job) {
+			used[job.URL] = true
+		}
+	}
+
+	taken := []string{}
+	for _, url := range urls {
+		if used[url] {
+			taken = append(taken, url)
+		}
+	}
+	return taken, nil
 }
 
-func (r *inmemJobRepository) PutJob(job *model.Job) error {
+func (r *inmemJobRepository) Save(ctx context.Context, job *model.Job) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.data[job.ID] = job
-	return nil
+	return r.save(job)
 }
 
-func (r *inmemJobRepository) UpdateJobs(jobs []*model.Job) error {
+// SaveAll saves all jobs atomically: if any job violates the URL uniqueness,
+// none of the jobs are saved. Jobs modified concurrently are skipped and
+// reported by a *repository.ConflictError.
+func (r *inmemJobRepository) SaveAll(ctx context.Context, jobs []*model.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// IDs of the jobs by tenant and URL
+	urls := map[[2]string]uint{}
+	for _, job := range jobs {
+		key := [2]string{tenantOf(job), job.URL}
+		if id, ok := urls[key]; ok && (id == 0 || id != job.ID) {
+			return repository.ErrDuplicate
+		}
+		urls[key] = job.ID
+		if r.urlTaken(job) {
+			return repository.ErrDuplicate
+		}
+	}
+
+	var conflicts []uint
+	for _, job := range jobs {
+		err := r.save(job)
+		if errors.Is(err, repository.ErrConflict) {
+			conflicts = append(conflicts, job.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &repository.ConflictError{IDs: conflicts}
+	}
 	return nil
 }
 
-func (r *inmemJobRepository) GetDueJobs(limit int) ([]*model.Job, error) {
+// GetDue returns up to limit scheduled jobs whose next run is due, ordered by priority and next run.
+// If limit == 0, all due jobs are returned.
+func (r *inmemJobRepository) GetDue(ctx context.Context, limit int) ([]*model.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return nil, err
+	}
+
+	jobs := []*model.Job{}
+	for _, job := range r.data {
+		if job.IsDue() && inTenant(ctx, job) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+		if jobs[i].NextRunAt.Equal(jobs[j].NextRunAt) {
+			return jobs[i].ID < jobs[j].ID
+		}
+		return jobs[i].NextRunAt.Before(jobs[j].NextRunAt)
+	})
+
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return cloneAll(jobs), nil
+}
+
+func (r *inmemJobRepository) List(ctx context.Context, filter *model.ListJobsFilter) ([]*model.Job, *pagination.Pagination, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	sortBy, sortOrder := sanitizeSort(filter.SortBy, filter.SortOrder)
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
 
 	jobs := []*model.Job{}
 	for _, job := range r.data {
-		if job.IsDue() {
+		if inTenant(ctx, job) && matches(job, filter) {
 			jobs = append(jobs, job)
-			if len(jobs) >= limit {
-				break
-			}
 		}
 	}
-	return jobs, nil
+	pagination.SetTotal(int64(len(jobs)))
+
+	sort.Slice(jobs, func(i, j int) bool {
+		cmp := compare(jobs[i], jobs[j], sortBy)
+		if cmp == 0 {
+			cmp = compareUint(jobs[i].ID, jobs[j].ID)
+		}
+		if sortOrder == "desc" {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	start := min(pagination.Offset(), len(jobs))
+	end := min(start+pagination.Limit(), len(jobs))
+	return cloneAll(jobs[start:end]), pagination, nil
+}
+
+// Count returns the number of jobs, soft deleted jobs are not counted.
+func (r *inmemJobRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if err := checkScope(ctx); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, job := range r.data {
+		if !job.IsDeleted() && inTenant(ctx, job) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Delete soft deletes a job and increments its version.
+// Deleting a missing or already deleted job is not an error.
+func (r *inmemJobRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := checkScope(ctx); err != nil {
+		return err
+	}
+
+	job, ok := r.data[uint(id)]
+	if !ok || job.IsDeleted() || !inTenant(ctx, job) {
+		return nil
+	}
+
+	now := time.Now()
+	job.DeletedAt = &now
+	job.UpdatedAt = now
+	job.Version++
+	return nil
+}
+
+// Restore undoes the soft delete of a job and increments its version.
+// Returns repository.ErrNotFound if there is no deleted job with the ID,
+// and repository.ErrDuplicate if another job of its tenant uses its URL.
+func (r *inmemJobRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := checkScope(ctx); err != nil {
+		return err
+	}
+
+	job, ok := r.data[uint(id)]
+	if !ok || !job.IsDeleted() || !inTenant(ctx, job) {
+		return repository.ErrNotFound
+	}
+	restored := clone(job)
+	restored.DeletedAt = nil
+	if r.urlTaken(restored) {
+		return repository.ErrDuplicate
+	}
+
+	job.DeletedAt = nil
+	job.UpdatedAt = time.Now()
+	job.Version++
+	return nil
+}
+
+// save inserts or updates the job, assigning an ID, version and timestamps like the database would.
+// Updates fail with a *repository.ConflictError if the version does not match the stored job.
+// The caller must hold the write lock.
+func (r *inmemJobRepository) save(job *model.Job) error {
+	if r.urlTaken(job) {
+		return repository.ErrDuplicate
+	}
+
+	now := time.Now()
+	if job.ID == 0 {
+		job.ID = r.nextID
+		r.nextID++
+		job.Version = 1
+		job.CreatedAt = now
+	} else {
+		existing, ok := r.data[job.ID]
+		if !ok || existing.Version != job.Version {
+			return &repository.ConflictError{IDs: []uint{job.ID}}
+		}
+		job.Version++
+		job.CreatedAt = existing.CreatedAt
+	}
+	job.UpdatedAt = now
+	if job.Tags == nil {
+		job.Tags = model.Tags{}
+	}
+	job.TenantID = tenantOf(job)
+
+	r.data[job.ID] = clone(job)
+	return nil
+}
+
+// urlTaken reports whether another job of the same tenant already uses the URL of job.
+// Like the partial unique index of the database, deleted jobs are ignored.
+func (r *inmemJobRepository) urlTaken(job *model.Job) bool {
+	if job.IsDeleted() {
+		return false
+	}
+	for id, existing := range r.data {
+		if existing.URL == job.URL && existing.TenantID == tenantOf(job) && id != job.ID && !existing.IsDeleted() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkScope returns repository.ErrNoTenant if ctx is neither scoped to a tenant nor allowed to see all tenants,
+// like the postgres implementation.
+func checkScope(ctx context.Context) error {
+	if _, ok := repository.TenantFromContext(ctx); !ok && !repository.AllTenantsAllowed(ctx) {
+		return repository.ErrNoTenant
+	}
+	return nil
+}
+
+// inTenant reports whether the job belongs to the tenant ctx is scoped to, or ctx is allowed to see all tenants.
+// The scope of ctx must have been checked by checkScope.
+func inTenant(ctx context.Context, job *model.Job) bool {
+	tenant, ok := repository.TenantFromContext(ctx)
+	return !ok || job.TenantID == tenant
+}
+
+// tenantOf returns the tenant of the job, the default tenant if it has none like in the database.
+func tenantOf(job *model.Job) string {
+	if job.TenantID == "" {
+		return model.DefaultTenant
+	}
+	return job.TenantID
+}
+
+func matches(job *model.Job, filter *model.ListJobsFilter) bool {
+	if job.IsDeleted() != filter.Deleted {
+		return false
+	}
+	if filter.URL != nil && !strings.Contains(strings.ToLower(job.URL), strings.ToLower(*filter.URL)) {
+		return false
+	}
+	if filter.Status != nil && job.Status != *filter.Status {
+		return false
+	}
+	if filter.Tag != nil && !job.Tags.Contains(*filter.Tag) {
+		return false
+	}
+	return true
+}
+
+func compare(a, b *model.Job, column string) int {
+	switch column {
+	case "status":
+		return strings.Compare(string(a.Status), string(b.Status))
+	case "url":
+		return strings.Compare(a.URL, b.URL)
+	case "next_run_at":
+		return a.NextRunAt.Compare(b.NextRunAt)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+func compareUint(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// sanitizeSort returns the column and direction for sorting,
+// using the same defaults and allowed values as the postgres repository.
+func sanitizeSort(sortBy, orderBy *string) (column, direction string) {
+	column = "created_at"
+	direction = "asc"
+
+	if sortBy != nil {
+		switch *sortBy {
+		case "status", "url", "created_at", "next_run_at":
+			column = *sortBy
+		}
+	}
+
+	if orderBy != nil && (*orderBy == "asc" || *orderBy == "desc") {
+		direction = *orderBy
+	}
+
+	return
+}
+
+// clone returns a deep copy of the job.
+func clone(job *model.Job) *model.Job {
+	c := *job
+	if job.Tags != nil {
+		c.Tags = append(model.Tags{}, job.Tags...)
+	}
+	if job.DispatchedAt != nil {
+		t := *job.DispatchedAt
+		c.DispatchedAt = &t
+	}
+	if job.IntervalChangedAt != nil {
+		t := *job.IntervalChangedAt
+		c.IntervalChangedAt = &t
+	}
+	if job.DeletedAt != nil {
+		t := *job.DeletedAt
+		c.DeletedAt = &t
+	}
+	return &c
+}
+
+func cloneAll(jobs []*model.Job) []*model.Job {
+	clones := make([]*model.Job, 0, len(jobs))
+	for _, job := range jobs {
+		clones = append(clones, clone(job))
+	}
+	return clones
 }
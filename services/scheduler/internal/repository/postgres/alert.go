@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type alertRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewAlertRepository(db *gorm.DB, dialect Dialect) *alertRepository {
+	return &alertRepository{db: db, dialect: dialect}
+}
+
+func (r *alertRepository) SaveRule(ctx context.Context, rule *model.AlertRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+func (r *alertRepository) GetRuleByID(ctx context.Context, id int) (*model.AlertRule, error) {
+	var rule model.AlertRule
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).First(&rule, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &rule, result.Error
+}
+
+func (r *alertRepository) ListRules(ctx context.Context, filter *model.ListAlertRulesFilter) ([]*model.AlertRule, *pagination.Pagination, error) {
+	var rules []*model.AlertRule
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.AlertRule{})
+
+	if filter.JobID != nil {
+		db = db.Where("job_id = ?", *filter.JobID)
+	}
+
+	if filter.Tag != nil {
+		db = db.Where("tag = ?", *filter.Tag)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order("id ASC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&rules)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return rules, pagination, nil
+}
+
+// RulesForJob returns all rules of the tenant of the job targeting the job directly or via one of its tags.
+func (r *alertRepository) RulesForJob(ctx context.Context, job *model.Job) ([]*model.AlertRule, error) {
+	var rules []*model.AlertRule
+
+	targets := r.db.Where("job_id = ?", job.ID)
+	if len(job.Tags) > 0 {
+		targets = targets.Or("tag IN ?", []string(job.Tags))
+	}
+
+	db := r.db.WithContext(ctx).Where("tenant_id = ?", job.TenantID).Where(targets)
+	if err := db.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *alertRepository) DeleteRule(ctx context.Context, id int) error {
+	return scopeTenant(ctx, r.db.WithContext(ctx)).Delete(&model.AlertRule{}, id).Error
+}
+
+// SaveEvent inserts an alert event unless an event for the same rule and observation exists.
+// It reports whether the event was created.
+func (r *alertRepository) SaveEvent(ctx context.Context, event *model.AlertEvent) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *alertRepository) GetEventByID(ctx context.Context, id int) (*model.AlertEvent, error) {
+	var event model.AlertEvent
+	result := scopeTenantJobs(ctx, r.db.WithContext(ctx)).First(&event, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &event, result.Error
+}
+
+func (r *alertRepository) ListEvents(ctx context.Context, filter *model.ListAlertsFilter) ([]*model.AlertEvent, *pagination.Pagination, error) {
+	var events []*model.AlertEvent
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := scopeTenantJobs(ctx, r.db.WithContext(ctx)).Model(&model.AlertEvent{})
+
+	if filter.JobID != nil {
+		db = db.Where("job_id = ?", *filter.JobID)
+	}
+
+	if filter.RuleID != nil {
+		db = db.Where("rule_id = ?", *filter.RuleID)
+	}
+
+	if filter.Type != nil {
+		db = db.Where("type = ?", *filter.Type)
+	}
+
+	if filter.From != nil {
+		db = db.Where(r.dialect.compare("triggered_at", ">="), *filter.From)
+	}
+
+	if filter.To != nil {
+		db = db.Where(r.dialect.compare("triggered_at", "<"), *filter.To)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(r.dialect.Time("triggered_at") + " DESC, id DESC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&events)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return events, pagination, nil
+}
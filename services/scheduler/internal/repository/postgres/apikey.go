@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *apiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Save(ctx context.Context, key *model.APIKey) error {
+	return r.db.WithContext(ctx).Save(key).Error
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id int) (*model.APIKey, error) {
+	var key model.APIKey
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).First(&key, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &key, result.Error
+}
+
+// GetActiveByHash returns the key with the hash unless it was revoked.
+// It authenticates requests, so it is not scoped to a tenant.
+func (r *apiKeyRepository) GetActiveByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	var key model.APIKey
+	result := r.db.WithContext(ctx).Where("key_hash = ? AND revoked_at IS NULL", hash).First(&key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &key, result.Error
+}
+
+func (r *apiKeyRepository) List(ctx context.Context, filter *model.ListAPIKeysFilter) ([]*model.APIKey, *pagination.Pagination, error) {
+	var keys []*model.APIKey
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.APIKey{})
+
+	if filter.Revoked != nil {
+		if *filter.Revoked {
+			db = db.Where("revoked_at IS NOT NULL")
+		} else {
+			db = db.Where("revoked_at IS NULL")
+		}
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order("id ASC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&keys)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return keys, pagination, nil
+}
+
+// Revoke marks a key as revoked, revoking a key twice keeps the first time.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int, at time.Time) error {
+	return scopeTenant(ctx, r.db.WithContext(ctx)).
+		Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+// auditRepository stores the audit log. It only appends entries,
+// updates and deletes are additionally rejected by database triggers.
+type auditRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewAuditRepository(db *gorm.DB, dialect Dialect) *auditRepository {
+	return &auditRepository{db: db, dialect: dialect}
+}
+
+func (r *auditRepository) AppendEntries(ctx context.Context, entries []*model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&entries).Error
+}
+
+func (r *auditRepository) ListEntries(ctx context.Context, filter *model.ListAuditFilter) ([]*model.AuditEntry, *pagination.Pagination, error) {
+	var entries []*model.AuditEntry
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := scopeTenantJobs(ctx, r.db.WithContext(ctx)).Model(&model.AuditEntry{})
+
+	if filter.JobID != nil {
+		db = db.Where("job_id = ?", *filter.JobID)
+	}
+
+	if filter.Actor != nil {
+		db = db.Where("actor = ?", *filter.Actor)
+	}
+
+	if filter.Action != nil {
+		db = db.Where("action = ?", *filter.Action)
+	}
+
+	if filter.From != nil {
+		db = db.Where(r.dialect.compare("created_at", ">="), *filter.From)
+	}
+
+	if filter.To != nil {
+		db = db.Where(r.dialect.compare("created_at", "<"), *filter.To)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order("id DESC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&entries)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return entries, pagination, nil
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+// Dialect holds the SQL that differs between the databases the repositories of this package run on.
+// Postgres is the default, the sqlite package provides the dialect of SQLite.
+type Dialect struct {
+	// Time returns the expression comparing and sorting the timestamp column or placeholder expr by instant.
+	Time func(expr string) string
+	// URLContains is the condition matching the URLs containing the pattern of its placeholder, ignoring case.
+	URLContains string
+	// HasTag returns the condition and its argument matching the jobs with the tag.
+	HasTag func(tag string) (string, any)
+}
+
+// Postgres is the dialect of PostgreSQL.
+var Postgres = Dialect{
+	Time:        func(expr string) string { return expr },
+	URLContains: "url ILIKE ?",
+	HasTag: func(tag string) (string, any) {
+		return "tags @> ?::jsonb", model.Tags{tag}
+	},
+}
+
+// compare returns the condition comparing the timestamp column to the time of a placeholder with op, e.g. "<".
+func (d Dialect) compare(column, op string) string {
+	return fmt.Sprintf("%s %s %s", d.Time(column), op, d.Time("?"))
+}
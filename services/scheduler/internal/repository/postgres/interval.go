@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type intervalChangeRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewIntervalChangeRepository(db *gorm.DB, dialect Dialect) *intervalChangeRepository {
+	return &intervalChangeRepository{db: db, dialect: dialect}
+}
+
+func (r *intervalChangeRepository) SaveIntervalChange(ctx context.Context, change *model.IntervalChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+func (r *intervalChangeRepository) ListIntervalChanges(ctx context.Context, jobID uint, filter *model.ListIntervalChangesFilter) ([]*model.IntervalChange, *pagination.Pagination, error) {
+	var changes []*model.IntervalChange
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.db.WithContext(ctx).Model(&model.IntervalChange{}).Where("job_id = ?", jobID)
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(r.dialect.Time("changed_at") + " DESC, id DESC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&changes)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return changes, pagination, nil
+}
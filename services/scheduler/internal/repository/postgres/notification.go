@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type deliveryRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewDeliveryRepository(db *gorm.DB, dialect Dialect) *deliveryRepository {
+	return &deliveryRepository{db: db, dialect: dialect}
+}
+
+func (r *deliveryRepository) SaveDelivery(ctx context.Context, delivery *model.NotificationDelivery) error {
+	return r.db.WithContext(ctx).Create(delivery).Error
+}
+
+func (r *deliveryRepository) ListDeliveries(ctx context.Context, alertEventID uint, filter *model.ListDeliveriesFilter) ([]*model.NotificationDelivery, *pagination.Pagination, error) {
+	var deliveries []*model.NotificationDelivery
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.db.WithContext(ctx).Model(&model.NotificationDelivery{}).Where("alert_event_id = ?", alertEventID)
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(r.dialect.Time("created_at") + " ASC, id ASC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&deliveries)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return deliveries, pagination, nil
+}
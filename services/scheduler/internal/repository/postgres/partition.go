@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// pricePartitionPrefix names the monthly partitions of price observations,
+	// e.g. price_observations_y2026m10.
+	pricePartitionPrefix = "price_observations_"
+	pricePartitionLayout = "y2006m01"
+
+	// archiveSchema holds partitions detached after their retention period.
+	archiveSchema = "archive"
+)
+
+// partitionRepository manages the monthly range partitions of price observations.
+// It relies on postgres declarative partitioning and is not available on SQLite.
+type partitionRepository struct {
+	db *gorm.DB
+}
+
+func NewPartitionRepository(db *gorm.DB) *partitionRepository {
+	return &partitionRepository{db: db}
+}
+
+// EnsurePricePartitions creates the missing partitions from the month of now up to ahead months in advance.
+// Observations already stored in the default partition are moved to the new partitions.
+// It returns the names of the created partitions.
+func (r *partitionRepository) EnsurePricePartitions(ctx context.Context, now time.Time, ahead int) ([]string, error) {
+	existing, err := r.pricePartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	month := startOfMonth(now)
+	for i := 0; i <= ahead; i++ {
+		if _, ok := existing[month]; !ok {
+			var name string
+			if err := r.db.WithContext(ctx).Raw("SELECT price_observations_ensure_partition(?)", month.Format(time.DateOnly)).Scan(&name).Error; err != nil {
+				return created, fmt.Errorf("failed to create partition for %s: %w", month.Format("2006-01"), err)
+			}
+			created = append(created, name)
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	return created, nil
+}
+
+// ExpirePriceObservations removes the observations made before 'before'. Partitions whose month ended
+// before it are dropped, or detached and moved to the archive schema if archive is set.
+// Expired observations left in the default partition are deleted.
+// It returns the names of the dropped or archived partitions.
+func (r *partitionRepository) ExpirePriceObservations(ctx context.Context, before time.Time, archive bool) ([]string, error) {
+	partitions, err := r.pricePartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for month, name := range partitions {
+		if month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if !archive {
+				return tx.Exec(fmt.Sprintf("DROP TABLE %s", quoteIdent(name))).Error
+			}
+			if err := tx.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", quoteIdent(archiveSchema))).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE price_observations DETACH PARTITION %s", quoteIdent(name))).Error; err != nil {
+				return err
+			}
+			return tx.Exec(fmt.Sprintf("ALTER TABLE %s SET SCHEMA %s", quoteIdent(name), quoteIdent(archiveSchema))).Error
+		})
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire partition %s: %w", name, err)
+		}
+		expired = append(expired, name)
+	}
+
+	if err := r.db.WithContext(ctx).Exec("DELETE FROM price_observations_default WHERE observed_at < ?", before).Error; err != nil {
+		return expired, err
+	}
+	return expired, nil
+}
+
+// pricePartitions returns the monthly partitions of price observations by the start of their month.
+func (r *partitionRepository) pricePartitions(ctx context.Context) (map[time.Time]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Raw(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'price_observations'`).Scan(&names).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price partitions: %w", err)
+	}
+
+	partitions := make(map[time.Time]string, len(names))
+	for _, name := range names {
+		// skips the default partition
+		month, err := time.Parse(pricePartitionLayout, strings.TrimPrefix(name, pricePartitionPrefix))
+		if err != nil {
+			continue
+		}
+		partitions[month] = name
+	}
+	return partitions, nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
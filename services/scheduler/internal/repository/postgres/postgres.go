@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -9,34 +10,61 @@ import (
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
 	"github.com/lorenzhoerb/cogniprice/shared/pagination"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type jobRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	dialect Dialect
 }
 
-func New(db *gorm.DB) *jobRepository {
-	return &jobRepository{db: db}
+// New returns the job repository of db, using the SQL of dialect where the databases differ.
+func New(db *gorm.DB, dialect Dialect) *jobRepository {
+	return &jobRepository{db: db, dialect: dialect}
 }
 
-func (r *jobRepository) Save(job *model.Job) error {
-	return r.db.Save(job).Error
+// Save inserts a new job or updates an existing job if its version still matches
+// the stored version, incrementing the version on success.
+// Returns a *repository.ConflictError if the job was modified or deleted concurrently.
+func (r *jobRepository) Save(ctx context.Context, job *model.Job) error {
+	return translateError(saveVersioned(r.db.WithContext(ctx), job))
 }
 
-func (r *jobRepository) SaveAll(jobs []*model.Job) error {
-	return r.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&jobs).Error
+// SaveAll saves all jobs in a single transaction like Save. Jobs modified
+// concurrently are skipped and reported by a *repository.ConflictError,
+// all other jobs are saved.
+func (r *jobRepository) SaveAll(ctx context.Context, jobs []*model.Job) error {
+	var conflicts []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, job := range jobs {
+			err := saveVersioned(tx, job)
+			if errors.Is(err, repository.ErrConflict) {
+				conflicts = append(conflicts, job.ID)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	if len(conflicts) > 0 {
+		return &repository.ConflictError{IDs: conflicts}
+	}
+	return nil
 }
 
-// Get due Jobs ,
-func (r *jobRepository) GetDue(limit int) ([]*model.Job, error) {
+// GetDue returns up to limit due jobs, ordered by priority (highest first) and next run.
+func (r *jobRepository) GetDue(ctx context.Context, limit int) ([]*model.Job, error) {
 	var jobs []*model.Job
-	db := r.db.
-		Where("next_run_at <= ?", time.Now()).
+	db := scopeTenant(ctx, r.db.WithContext(ctx)).
+		Where(r.dialect.compare("next_run_at", "<="), time.Now()).
+		Where("deleted_at IS NULL").
 		Where("status = ? ", model.JobStatusScheduled).
-		Order("next_run_at ASC")
+		Order(fmt.Sprintf("priority DESC, %s ASC, id ASC", r.dialect.Time("next_run_at")))
 
 	if limit > 0 {
 		db = db.Limit(limit)
@@ -50,18 +78,29 @@ func (r *jobRepository) GetDue(limit int) ([]*model.Job, error) {
 	return jobs, nil
 }
 
-func (r *jobRepository) GetByID(id int) (*model.Job, error) {
+func (r *jobRepository) GetByID(ctx context.Context, id int) (*model.Job, error) {
 	var job model.Job
-	result := r.db.First(&job, id) // "id = ?" by default
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).Where("deleted_at IS NULL").First(&job, id) // "id = ?" by default
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, repository.ErrNotFound // or return custom ErrNotFound
 	}
 	return &job, result.Error
 }
 
-func (r *jobRepository) GetByURL(url string) (*model.Job, error) {
+// GetDeleted returns a soft deleted job.
+// Returns repository.ErrNotFound if there is no deleted job with the ID.
+func (r *jobRepository) GetDeleted(ctx context.Context, id int) (*model.Job, error) {
 	var job model.Job
-	result := r.db.Where("url = ?", url).Take(&job)
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).Where("deleted_at IS NOT NULL").First(&job, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &job, result.Error
+}
+
+func (r *jobRepository) GetByURL(ctx context.Context, url string) (*model.Job, error) {
+	var job model.Job
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).Where("url = ? AND deleted_at IS NULL", url).Take(&job)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound // Not found, return nil without error
@@ -71,17 +110,36 @@ func (r *jobRepository) GetByURL(url string) (*model.Job, error) {
 	return &job, nil
 }
 
-func (r *jobRepository) List(filter *model.ListJobsFilter) ([]*model.Job, *pagination.Pagination, error) {
+// TakenURLs returns the URLs of urls used by a job, soft deleted jobs are ignored.
+func (r *jobRepository) TakenURLs(ctx context.Context, urls []string) ([]string, error) {
+	taken := []string{}
+	if len(urls) == 0 {
+		return taken, nil
+	}
+	err := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{}).Where("url IN ? AND deleted_at IS NULL", urls).Pluck("url", &taken).Error
+	return taken, err
+}
+
+func (r *jobRepository) List(ctx context.Context, filter *model.ListJobsFilter) ([]*model.Job, *pagination.Pagination, error) {
 	var jobs []*model.Job
 
 	sortBy, sortOrder := sanitizeSort(filter.SortBy, filter.SortOrder)
+	if sortBy == "created_at" || sortBy == "next_run_at" {
+		sortBy = r.dialect.Time(sortBy)
+	}
 	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
 
-	db := r.db.Model(&model.Job{})
+	db := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{})
+
+	if filter.Deleted {
+		db = db.Where("deleted_at IS NOT NULL")
+	} else {
+		db = db.Where("deleted_at IS NULL")
+	}
 
 	// Apply URL filter if provided
 	if filter.URL != nil {
-		db = db.Where("url ILIKE ?", "%"+*filter.URL+"%")
+		db = db.Where(r.dialect.URLContains, "%"+*filter.URL+"%")
 	}
 
 	// Apply Status filter if provided
@@ -89,6 +147,11 @@ func (r *jobRepository) List(filter *model.ListJobsFilter) ([]*model.Job, *pagin
 		db = db.Where("status = ?", filter.Status)
 	}
 
+	// Apply Tag filter if provided
+	if filter.Tag != nil {
+		db = db.Where(r.dialect.HasTag(*filter.Tag))
+	}
+
 	// Get total count (ignoring limit/offset)
 	if err := db.Count(&pagination.Total).Error; err != nil {
 		return nil, nil, err
@@ -96,7 +159,7 @@ func (r *jobRepository) List(filter *model.ListJobsFilter) ([]*model.Job, *pagin
 
 	// Apply pagination
 	result := db.
-		Order(fmt.Sprintf("%s %s", sortBy, sortOrder)).
+		Order(fmt.Sprintf("%s %s, id %s", sortBy, sortOrder, sortOrder)).
 		Limit(pagination.Limit()).
 		Offset(pagination.Offset()).
 		Find(&jobs)
@@ -108,8 +171,99 @@ func (r *jobRepository) List(filter *model.ListJobsFilter) ([]*model.Job, *pagin
 	return jobs, pagination, nil
 }
 
-func (r *jobRepository) Delete(id int) error {
-	return r.db.Delete(&model.Job{}, id).Error
+// Count returns the number of jobs, soft deleted jobs are not counted.
+func (r *jobRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{}).Where("deleted_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// Delete soft deletes a job and increments its version.
+// Deleting a missing or already deleted job is not an error.
+func (r *jobRepository) Delete(ctx context.Context, id int) error {
+	return scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{
+			"deleted_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+}
+
+// Restore undoes the soft delete of a job and increments its version.
+// Returns repository.ErrNotFound if there is no deleted job with the ID,
+// and repository.ErrDuplicate if another job of its tenant uses its URL.
+func (r *jobRepository) Restore(ctx context.Context, id int) error {
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// saveVersioned creates the job or updates it conditionally on its version.
+func saveVersioned(db *gorm.DB, job *model.Job) error {
+	if job.ID == 0 {
+		job.Version = 1
+		return db.Create(job).Error
+	}
+
+	expected := job.Version
+	job.Version = expected + 1
+	result := db.Model(job).
+		Where("version = ?", expected).
+		Select("*").
+		Omit("created_at").
+		Updates(job)
+
+	if result.Error != nil {
+		job.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		job.Version = expected
+		return &repository.ConflictError{IDs: []uint{job.ID}}
+	}
+	return nil
+}
+
+// scopeTenant restricts the query to the rows of the tenant ctx is scoped to. Contexts allowed to see all
+// tenants are not restricted, the queries made with any other context fail with repository.ErrNoTenant.
+func scopeTenant(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return scope(ctx, db, "tenant_id = ?")
+}
+
+// scopeTenantJobs restricts the query to the rows of the jobs of the tenant ctx is scoped to like scopeTenant.
+// The rows are joined to their job by the job_id column.
+func scopeTenantJobs(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return scope(ctx, db, "job_id IN (SELECT id FROM jobs WHERE tenant_id = ?)")
+}
+
+// scope adds the condition on the tenant of ctx to the query, or fails it if ctx is neither
+// scoped to a tenant nor allowed to see all tenants.
+func scope(ctx context.Context, db *gorm.DB, condition string) *gorm.DB {
+	if tenant, ok := repository.TenantFromContext(ctx); ok {
+		return db.Where(condition, tenant)
+	}
+	if !repository.AllTenantsAllowed(ctx) {
+		_ = db.AddError(repository.ErrNoTenant)
+	}
+	return db
+}
+
+// translateError maps gorm errors to repository errors.
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.ErrDuplicate
+	}
+	return err
 }
 
 // sanitizeSort safely returns the column and direction for sorting.
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) *priceRepository {
+	return &priceRepository{db: db}
+}
+
+func (r *priceRepository) SaveObservation(ctx context.Context, observation *model.PriceObservation) error {
+	return r.db.WithContext(ctx).Create(observation).Error
+}
+
+func (r *priceRepository) LatestObservation(ctx context.Context, jobID uint) (*model.PriceObservation, error) {
+	var observation model.PriceObservation
+	result := r.db.WithContext(ctx).
+		Where("job_id = ?", jobID).
+		Order("observed_at DESC, id DESC").
+		Take(&observation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &observation, result.Error
+}
+
+func (r *priceRepository) ListObservations(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.PriceObservation, *pagination.Pagination, error) {
+	var observations []*model.PriceObservation
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.filterObservations(ctx, jobID, filter)
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(fmt.Sprintf("observed_at %s", sanitizeSortOrder(filter.SortOrder))).
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&observations)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return observations, pagination, nil
+}
+
+// ListDaily returns the daily rollups of a job, holding the minimum, maximum and last
+// observed amount of each day (UTC). The rollups are maintained on every insert,
+// so they cover days whose observations were already expired.
+// From and To select whole days, a day is included if any part of it is in the range.
+func (r *priceRepository) ListDaily(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.DailyPrice, *pagination.Pagination, error) {
+	var days []*model.DailyPrice
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.db.WithContext(ctx).Table("price_daily").Where("job_id = ?", jobID)
+
+	if filter.From != nil {
+		db = db.Where("day >= ?", startOfDay(*filter.From))
+	}
+
+	if filter.To != nil {
+		to := startOfDay(*filter.To)
+		if filter.To.After(to) {
+			to = to.AddDate(0, 0, 1)
+		}
+		db = db.Where("day < ?", to)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Select("day, min, max, last, currency, count").
+		Order(fmt.Sprintf("day %s", sanitizeSortOrder(filter.SortOrder))).
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Scan(&days)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return days, pagination, nil
+}
+
+func (r *priceRepository) filterObservations(ctx context.Context, jobID uint, filter *model.ListPricesFilter) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&model.PriceObservation{}).Where("job_id = ?", jobID)
+
+	if filter.From != nil {
+		db = db.Where("observed_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		db = db.Where("observed_at < ?", *filter.To)
+	}
+
+	return db
+}
+
+// sanitizeSortOrder returns a safe sort direction, defaulting to ascending.
+func sanitizeSortOrder(order *string) string {
+	if order != nil && (*order == "asc" || *order == "desc") {
+		return *order
+	}
+	return "asc"
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
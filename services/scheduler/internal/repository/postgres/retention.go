@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize limits the number of jobs purged in a single transaction.
+const purgeBatchSize = 500
+
+type retentionRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewRetentionRepository(db *gorm.DB, dialect Dialect) *retentionRepository {
+	return &retentionRepository{db: db, dialect: dialect}
+}
+
+// PurgeDeletedJobs permanently removes the jobs soft deleted before deletedBefore,
+// including their prices, alerts, deliveries, interval changes and runs.
+// It returns the number of purged jobs.
+func (r *retentionRepository) PurgeDeletedJobs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	for {
+		var ids []uint
+		if err := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.Job{}).
+			Where("deleted_at IS NOT NULL AND "+r.dialect.compare("deleted_at", "<"), deletedBefore).
+			Order("id").Limit(purgeBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return purgeJobs(tx, ids)
+		}); err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// purgeJobs deletes the jobs with the given IDs and all data referencing them.
+func purgeJobs(tx *gorm.DB, ids []uint) error {
+	events := tx.Model(&model.AlertEvent{}).Select("id").Where("job_id IN ?", ids)
+	if err := tx.Where("alert_event_id IN (?)", events).Delete(&model.NotificationDelivery{}).Error; err != nil {
+		return err
+	}
+
+	for _, related := range []any{
+		&model.AlertEvent{},
+		&model.AlertRule{},
+		&model.PriceObservation{},
+		&model.IntervalChange{},
+		&model.JobRun{},
+	} {
+		if err := tx.Where("job_id IN ?", ids).Delete(related).Error; err != nil {
+			return err
+		}
+	}
+
+	// daily price rollups are only maintained on postgres
+	if tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec("DELETE FROM price_daily WHERE job_id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("id IN ? AND deleted_at IS NOT NULL", ids).Delete(&model.Job{}).Error
+}
@@ -0,0 +1,93 @@
+package postgres_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/db"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/postgres"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openSQLite returns a migrated SQLite database, the repositories of the package are shared by both drivers.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := db.Connect(&config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "scheduler.db"),
+	})
+	require.NoError(t, err)
+
+	migrator, err := db.NewMigrator(gormDB)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return gormDB
+}
+
+func createJobs(t *testing.T, gormDB *gorm.DB, n int, deletedAt *time.Time) []uint {
+	t.Helper()
+	jobs := make([]*model.Job, n)
+	for i := range jobs {
+		jobs[i] = &model.Job{
+			URL:       fmt.Sprintf("https://shop.test/%d-%d", time.Now().UnixNano(), i),
+			Status:    model.JobStatusScheduled,
+			Interval:  time.Hour,
+			NextRunAt: time.Now(),
+			Tags:      model.Tags{},
+			DeletedAt: deletedAt,
+		}
+	}
+	require.NoError(t, gormDB.CreateInBatches(jobs, 100).Error)
+
+	ids := make([]uint, n)
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+func TestPurgeDeletedJobs(t *testing.T) {
+	gormDB := openSQLite(t)
+	repo := postgres.NewRetentionRepository(gormDB, sqlite.Dialect)
+
+	now := time.Now()
+	expired := now.Add(-31 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	// more expired jobs than purged in a single batch
+	expiredIDs := createJobs(t, gormDB, 501, &expired)
+	recentIDs := createJobs(t, gormDB, 2, &recent)
+	activeIDs := createJobs(t, gormDB, 2, nil)
+
+	observation := &model.PriceObservation{JobID: expiredIDs[0], ObservedAt: now, Amount: 100, Currency: "EUR", Availability: model.AvailabilityInStock}
+	require.NoError(t, gormDB.Create(observation).Error)
+	run := model.NewJobRun(&model.Job{ID: expiredIDs[0]}, now)
+	require.NoError(t, gormDB.Create(run).Error)
+
+	purged, err := repo.PurgeDeletedJobs(repository.AllTenants(t.Context()), now.Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 501, purged)
+
+	var remaining []uint
+	require.NoError(t, gormDB.Model(&model.Job{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, append(recentIDs, activeIDs...), remaining)
+
+	var observations, runs int64
+	require.NoError(t, gormDB.Model(&model.PriceObservation{}).Count(&observations).Error)
+	require.NoError(t, gormDB.Model(&model.JobRun{}).Count(&runs).Error)
+	assert.Zero(t, observations, "prices of purged jobs are removed")
+	assert.Zero(t, runs, "runs of purged jobs are removed")
+
+	purged, err = repo.PurgeDeletedJobs(repository.AllTenants(t.Context()), now.Add(-30*24*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type runRepository struct {
+	db *gorm.DB
+}
+
+func NewRunRepository(db *gorm.DB) *runRepository {
+	return &runRepository{db: db}
+}
+
+func (r *runRepository) SaveRun(ctx context.Context, run *model.JobRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// StartRuns saves the dispatched jobs and inserts their runs in a single transaction,
+// runs[i] being the run of jobs[i]. Jobs modified concurrently are skipped together
+// with their runs and reported by a *repository.ConflictError, all other jobs and runs are saved.
+func (r *runRepository) StartRuns(ctx context.Context, jobs []*model.Job, runs []*model.JobRun) error {
+	var conflicts []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var started []*model.JobRun
+		for i, job := range jobs {
+			err := saveVersioned(tx, job)
+			if errors.Is(err, repository.ErrConflict) {
+				conflicts = append(conflicts, job.ID)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			started = append(started, runs[i])
+		}
+		if len(started) == 0 {
+			return nil
+		}
+		return tx.Create(&started).Error
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	if len(conflicts) > 0 {
+		return &repository.ConflictError{IDs: conflicts}
+	}
+	return nil
+}
+
+func (r *runRepository) GetRun(ctx context.Context, id uint) (*model.JobRun, error) {
+	var run model.JobRun
+	result := r.db.WithContext(ctx).First(&run, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &run, result.Error
+}
+
+// LatestOpenRun returns the latest unfinished run of a job.
+// Runs are created in dispatch order, so the highest ID is the latest run.
+func (r *runRepository) LatestOpenRun(ctx context.Context, jobID uint) (*model.JobRun, error) {
+	var run model.JobRun
+	result := r.db.WithContext(ctx).
+		Where("job_id = ? AND finished_at IS NULL", jobID).
+		Order("id DESC").
+		Take(&run)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &run, result.Error
+}
+
+func (r *runRepository) ListRuns(ctx context.Context, jobID uint, filter *model.ListRunsFilter) ([]*model.JobRun, *pagination.Pagination, error) {
+	var runs []*model.JobRun
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.db.WithContext(ctx).Model(&model.JobRun{}).Where("job_id = ?", jobID)
+
+	if filter.Outcome != nil {
+		db = db.Where("outcome = ?", *filter.Outcome)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order("id DESC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&runs)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return runs, pagination, nil
+}
@@ -0,0 +1,61 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/postgres"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartRuns(t *testing.T) {
+	gormDB := openSQLite(t)
+	jobRepo := postgres.New(gormDB, sqlite.Dialect)
+	runRepo := postgres.NewRunRepository(gormDB)
+	ctx := repository.AllTenants(t.Context())
+
+	ids := createJobs(t, gormDB, 2, nil)
+	started, err := jobRepo.GetByID(ctx, int(ids[0]))
+	require.NoError(t, err)
+	modified, err := jobRepo.GetByID(ctx, int(ids[1]))
+	require.NoError(t, err)
+
+	// modify the second job after it was read
+	concurrent := *modified
+	require.NoError(t, jobRepo.Save(ctx, &concurrent))
+
+	dispatchedAt := time.Now()
+	runs := []*model.JobRun{
+		model.NewManualJobRun(started, dispatchedAt),
+		model.NewManualJobRun(modified, dispatchedAt),
+	}
+	for _, job := range []*model.Job{started, modified} {
+		job.Status = model.JobStatusInProgress
+		job.DispatchedAt = &dispatchedAt
+	}
+
+	err = runRepo.StartRuns(ctx, []*model.Job{started, modified}, runs)
+	var conflict *repository.ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, []uint{modified.ID}, conflict.IDs)
+
+	stored, err := jobRepo.GetByID(ctx, int(started.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusInProgress, stored.Status)
+
+	run, err := runRepo.LatestOpenRun(ctx, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, runs[0].ID, run.ID)
+	assert.Equal(t, model.JobStatusScheduled, run.PreviousStatus)
+
+	stored, err = jobRepo.GetByID(ctx, int(modified.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusScheduled, stored.Status)
+	_, err = runRepo.LatestOpenRun(ctx, modified.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db      *gorm.DB
+	dialect Dialect
+}
+
+func NewWebhookRepository(db *gorm.DB, dialect Dialect) *webhookRepository {
+	return &webhookRepository{db: db, dialect: dialect}
+}
+
+func (r *webhookRepository) SaveSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	result := scopeTenant(ctx, r.db.WithContext(ctx)).First(&subscription, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &subscription, result.Error
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, filter *model.ListWebhooksFilter) ([]*model.WebhookSubscription, *pagination.Pagination, error) {
+	var subscriptions []*model.WebhookSubscription
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := scopeTenant(ctx, r.db.WithContext(ctx)).Model(&model.WebhookSubscription{})
+
+	if filter.Enabled != nil {
+		db = db.Where("enabled = ?", *filter.Enabled)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order("id ASC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&subscriptions)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return subscriptions, pagination, nil
+}
+
+// EnabledSubscriptions returns all subscriptions that are not disabled.
+func (r *webhookRepository) EnabledSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subscriptions []*model.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription removes a subscription together with its delivery log.
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.WebhookSubscription{}, id).Error
+	})
+}
+
+// SaveDelivery records the delivery and updates the failure count of its subscription.
+// A successful delivery resets the count, a failed one increments it and disables the
+// subscription once maxFailures consecutive deliveries failed. It reports whether the
+// subscription was disabled by this delivery.
+func (r *webhookRepository) SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery, maxFailures int) (bool, error) {
+	disabled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+
+		subscription := tx.Model(&model.WebhookSubscription{}).Where("id = ?", delivery.SubscriptionID)
+		if delivery.Status == model.DeliveryStatusDelivered {
+			return subscription.Update("consecutive_failures", 0).Error
+		}
+		if err := subscription.Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.WebhookSubscription{}).
+			Where("id = ? AND enabled = ? AND consecutive_failures >= ?", delivery.SubscriptionID, true, maxFailures).
+			Updates(map[string]any{"enabled": false, "disabled_at": time.Now()})
+		disabled = result.RowsAffected > 0
+		return result.Error
+	})
+	return disabled, err
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, filter *model.ListWebhookDeliveriesFilter) ([]*model.WebhookDelivery, *pagination.Pagination, error) {
+	var deliveries []*model.WebhookDelivery
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+
+	if filter.Status != nil {
+		db = db.Where("status = ?", *filter.Status)
+	}
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(r.dialect.Time("created_at") + " DESC, id DESC").
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&deliveries)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return deliveries, pagination, nil
+}
@@ -0,0 +1,561 @@
+// Package repotest provides contract test suites for the repositories.
+//
+// Every job repository backend must pass the suite, so the service and the
+// scheduler behave the same regardless of the storage in use:
+//
+//	func TestJobRepositoryContract(t *testing.T) {
+//		repotest.RunJobRepositoryContract(t, func(t *testing.T) repotest.JobRepository {
+//			return inmem.New()
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/scheduler"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// JobRepository is the union of the repository interfaces required by the
+// job service and the scheduler.
+type JobRepository interface {
+	service.JobRepository
+	scheduler.JobRepository
+}
+
+// Factory returns a new, empty repository for a single test.
+type Factory func(t *testing.T) JobRepository
+
+// RunJobRepositoryContract runs the contract test suite against the repositories created by newRepo.
+func RunJobRepositoryContract(t *testing.T, newRepo Factory) {
+	t.Run("Save assigns IDs", func(t *testing.T) { testSaveAssignsID(t, newRepo(t)) })
+	t.Run("Save updates existing job", func(t *testing.T) { testSaveUpdates(t, newRepo(t)) })
+	t.Run("Save rejects duplicate URL", func(t *testing.T) { testSaveRejectsDuplicateURL(t, newRepo(t)) })
+	t.Run("GetByID returns not found", func(t *testing.T) { testGetByIDNotFound(t, newRepo(t)) })
+	t.Run("GetByURL", func(t *testing.T) { testGetByURL(t, newRepo(t)) })
+	t.Run("TakenURLs", func(t *testing.T) { testTakenURLs(t, newRepo(t)) })
+	t.Run("reads return copies", func(t *testing.T) { testCopyOnRead(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("Restore", func(t *testing.T) { testRestore(t, newRepo(t)) })
+	t.Run("URL of a deleted job can be reused", func(t *testing.T) { testReuseDeletedURL(t, newRepo(t)) })
+	t.Run("List deleted jobs", func(t *testing.T) { testListDeleted(t, newRepo(t)) })
+	t.Run("List filters", func(t *testing.T) { testListFilters(t, newRepo(t)) })
+	t.Run("List sorts", func(t *testing.T) { testListSorts(t, newRepo(t)) })
+	t.Run("List paginates", func(t *testing.T) { testListPaginates(t, newRepo(t)) })
+	t.Run("List paginates jobs with equal sort values", func(t *testing.T) { testListPaginatesTies(t, newRepo(t)) })
+	t.Run("GetDue returns due jobs ordered by next run", func(t *testing.T) { testGetDue(t, newRepo(t)) })
+	t.Run("GetDue respects limit", func(t *testing.T) { testGetDueLimit(t, newRepo(t)) })
+	t.Run("GetDue orders by priority", func(t *testing.T) { testGetDuePriority(t, newRepo(t)) })
+	t.Run("SaveAll updates jobs", func(t *testing.T) { testSaveAll(t, newRepo(t)) })
+	t.Run("Save rejects stale version", func(t *testing.T) { testSaveRejectsStaleVersion(t, newRepo(t)) })
+	t.Run("SaveAll skips conflicting jobs", func(t *testing.T) { testSaveAllSkipsConflicts(t, newRepo(t)) })
+	t.Run("URLs are unique per tenant", func(t *testing.T) { testURLUniquePerTenant(t, newRepo(t)) })
+	t.Run("queries are scoped to the tenant", func(t *testing.T) { testTenantScope(t, newRepo(t)) })
+	t.Run("Count", func(t *testing.T) { testCount(t, newRepo(t)) })
+}
+
+// allTenants returns the context of the test allowed to see all tenants, like the scheduler's.
+func allTenants(t *testing.T) context.Context {
+	return repository.AllTenants(t.Context())
+}
+
+func newJob(url string) *model.Job {
+	return &model.Job{
+		URL:       url,
+		Interval:  time.Hour,
+		Status:    model.JobStatusScheduled,
+		Tags:      model.Tags{},
+		NextRunAt: time.Now().Add(time.Hour),
+	}
+}
+
+func newTenantJob(tenant, url string) *model.Job {
+	job := newJob(url)
+	job.TenantID = tenant
+	return job
+}
+
+func saveJob(t *testing.T, repo JobRepository, job *model.Job) *model.Job {
+	t.Helper()
+	require.NoError(t, repo.Save(allTenants(t), job))
+	return job
+}
+
+func urls(jobs []*model.Job) []string {
+	result := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, job.URL)
+	}
+	return result
+}
+
+func testSaveAssignsID(t *testing.T, repo JobRepository) {
+	a := saveJob(t, repo, newJob("https://shop.test/a"))
+	b := saveJob(t, repo, newJob("https://shop.test/b"))
+
+	assert.NotZero(t, a.ID)
+	assert.NotZero(t, b.ID)
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.False(t, a.CreatedAt.IsZero())
+
+	got, err := repo.GetByID(allTenants(t), int(a.ID))
+	require.NoError(t, err)
+	assert.Equal(t, a.URL, got.URL)
+	assert.Equal(t, a.Interval, got.Interval)
+	assert.Equal(t, a.Status, got.Status)
+}
+
+func testSaveUpdates(t *testing.T, repo JobRepository) {
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+
+	job.Status = model.JobStatusPaused
+	require.NoError(t, repo.Save(allTenants(t), job))
+
+	got, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPaused, got.Status)
+}
+
+func testSaveRejectsDuplicateURL(t *testing.T, repo JobRepository) {
+	saveJob(t, repo, newJob("https://shop.test/a"))
+
+	err := repo.Save(allTenants(t), newJob("https://shop.test/a"))
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+}
+
+func testGetByIDNotFound(t *testing.T, repo JobRepository) {
+	_, err := repo.GetByID(allTenants(t), 4711)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testGetByURL(t *testing.T, repo JobRepository) {
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+
+	got, err := repo.GetByURL(allTenants(t), "https://shop.test/a")
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, got.ID)
+
+	_, err = repo.GetByURL(allTenants(t), "https://shop.test/missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testTakenURLs(t *testing.T, repo JobRepository) {
+	saveJob(t, repo, newJob("https://shop.test/a"))
+	deleted := saveJob(t, repo, newJob("https://shop.test/b"))
+	require.NoError(t, repo.Delete(allTenants(t), int(deleted.ID)))
+
+	taken, err := repo.TakenURLs(allTenants(t), []string{"https://shop.test/a", "https://shop.test/b", "https://shop.test/c"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"https://shop.test/a"}, taken, "the URL of a deleted job is not taken")
+
+	taken, err = repo.TakenURLs(allTenants(t), nil)
+	require.NoError(t, err)
+	assert.Empty(t, taken)
+}
+
+func testCopyOnRead(t *testing.T, repo JobRepository) {
+	job := newJob("https://shop.test/a")
+	job.Tags = model.Tags{"retailer-a"}
+	saveJob(t, repo, job)
+
+	// mutating the saved object must not change the stored job
+	job.Status = model.JobStatusFailed
+
+	got, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusScheduled, got.Status)
+
+	// mutating a read object must not change the stored job
+	got.Status = model.JobStatusPaused
+	got.Tags[0] = "changed"
+
+	again, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusScheduled, again.Status)
+	assert.Equal(t, model.Tags{"retailer-a"}, again.Tags)
+}
+
+func testDelete(t *testing.T, repo JobRepository) {
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+
+	require.NoError(t, repo.Delete(allTenants(t), int(job.ID)))
+
+	_, err := repo.GetByID(allTenants(t), int(job.ID))
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repo.GetByURL(allTenants(t), job.URL)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// deleting a deleted or missing job is not an error
+	assert.NoError(t, repo.Delete(allTenants(t), int(job.ID)))
+	assert.NoError(t, repo.Delete(allTenants(t), 4711))
+
+	// the job read before deleting it is stale
+	job.Status = model.JobStatusPaused
+	assert.ErrorIs(t, repo.Save(allTenants(t), job), repository.ErrConflict)
+}
+
+func testRestore(t *testing.T, repo JobRepository) {
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+
+	assert.ErrorIs(t, repo.Restore(allTenants(t), int(job.ID)), repository.ErrNotFound, "job is not deleted")
+	_, err := repo.GetDeleted(allTenants(t), int(job.ID))
+	assert.ErrorIs(t, err, repository.ErrNotFound, "job is not deleted")
+
+	require.NoError(t, repo.Delete(allTenants(t), int(job.ID)))
+	deleted, err := repo.GetDeleted(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+
+	require.NoError(t, repo.Restore(allTenants(t), int(job.ID)))
+
+	got, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.Nil(t, got.DeletedAt)
+	assert.EqualValues(t, 3, got.Version)
+}
+
+func testReuseDeletedURL(t *testing.T, repo JobRepository) {
+	deleted := saveJob(t, repo, newJob("https://shop.test/a"))
+	require.NoError(t, repo.Delete(allTenants(t), int(deleted.ID)))
+
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+	assert.NotEqual(t, deleted.ID, job.ID)
+
+	got, err := repo.GetByURL(allTenants(t), "https://shop.test/a")
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, got.ID)
+
+	// a second deleted job with the same URL
+	require.NoError(t, repo.Delete(allTenants(t), int(job.ID)))
+	again := saveJob(t, repo, newJob("https://shop.test/a"))
+
+	assert.ErrorIs(t, repo.Restore(allTenants(t), int(deleted.ID)), repository.ErrDuplicate, "URL is used by another job")
+	_, err = repo.GetDeleted(allTenants(t), int(deleted.ID))
+	assert.NoError(t, err, "job stays deleted")
+
+	require.NoError(t, repo.Delete(allTenants(t), int(again.ID)))
+	assert.NoError(t, repo.Restore(allTenants(t), int(deleted.ID)))
+}
+
+func testListDeleted(t *testing.T, repo JobRepository) {
+	active := saveJob(t, repo, newJob("https://shop.test/active"))
+	deleted := saveJob(t, repo, newJob("https://shop.test/deleted"))
+	require.NoError(t, repo.Delete(allTenants(t), int(deleted.ID)))
+
+	jobs, p, err := repo.List(allTenants(t), &model.ListJobsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{active.URL}, urls(jobs))
+	assert.EqualValues(t, 1, p.Total)
+
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{Deleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{deleted.URL}, urls(jobs))
+	assert.NotNil(t, jobs[0].DeletedAt)
+}
+
+func testListFilters(t *testing.T, repo JobRepository) {
+	a := newJob("https://Shop-A.test/product")
+	a.Tags = model.Tags{"retailer-a"}
+	saveJob(t, repo, a)
+
+	b := newJob("https://shop-b.test/product")
+	b.Status = model.JobStatusPaused
+	b.Tags = model.Tags{"retailer-b"}
+	saveJob(t, repo, b)
+
+	urlFilter := "shop-a"
+	jobs, p, err := repo.List(allTenants(t), &model.ListJobsFilter{URL: &urlFilter})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.URL}, urls(jobs), "URL filter is a case-insensitive substring match")
+	assert.EqualValues(t, 1, p.Total)
+
+	status := model.JobStatusPaused
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{Status: &status})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b.URL}, urls(jobs))
+
+	tag := "retailer-a"
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{Tag: &tag})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.URL}, urls(jobs))
+}
+
+func testListSorts(t *testing.T, repo JobRepository) {
+	for _, url := range []string{"https://b.test", "https://c.test", "https://a.test"} {
+		saveJob(t, repo, newJob(url))
+	}
+
+	sortBy, asc, desc := "url", "asc", "desc"
+	jobs, _, err := repo.List(allTenants(t), &model.ListJobsFilter{SortBy: &sortBy, SortOrder: &asc})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://a.test", "https://b.test", "https://c.test"}, urls(jobs))
+
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{SortBy: &sortBy, SortOrder: &desc})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://c.test", "https://b.test", "https://a.test"}, urls(jobs))
+}
+
+func testListPaginates(t *testing.T, repo JobRepository) {
+	for i := range 5 {
+		saveJob(t, repo, newJob(fmt.Sprintf("https://shop.test/%d", i)))
+	}
+
+	sortBy := "url"
+	jobs, p, err := repo.List(allTenants(t), &model.ListJobsFilter{SortBy: &sortBy, Page: 2, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://shop.test/2", "https://shop.test/3"}, urls(jobs))
+	assert.EqualValues(t, 5, p.Total)
+	assert.Equal(t, 3, p.TotalPages())
+
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{SortBy: &sortBy, Page: 4, PageSize: 2})
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func testListPaginatesTies(t *testing.T, repo JobRepository) {
+	var ids []uint
+	for i := range 5 {
+		ids = append(ids, saveJob(t, repo, newJob(fmt.Sprintf("https://shop.test/%d", i))).ID)
+	}
+
+	// all jobs have the same status, pages are ordered by ID so every job is listed once
+	sortBy := "status"
+	var listed []uint
+	for page := 1; page <= 3; page++ {
+		jobs, _, err := repo.List(allTenants(t), &model.ListJobsFilter{SortBy: &sortBy, Page: page, PageSize: 2})
+		require.NoError(t, err)
+		for _, job := range jobs {
+			listed = append(listed, job.ID)
+		}
+	}
+	assert.Equal(t, ids, listed)
+}
+
+func testGetDue(t *testing.T, repo JobRepository) {
+	now := time.Now()
+
+	later := newJob("https://shop.test/due-later")
+	later.NextRunAt = now.Add(-time.Minute)
+	saveJob(t, repo, later)
+
+	earlier := newJob("https://shop.test/due-earlier")
+	earlier.NextRunAt = now.Add(-time.Hour)
+	saveJob(t, repo, earlier)
+
+	saveJob(t, repo, newJob("https://shop.test/future"))
+
+	paused := newJob("https://shop.test/paused")
+	paused.NextRunAt = now.Add(-time.Hour)
+	paused.Status = model.JobStatusPaused
+	saveJob(t, repo, paused)
+
+	deleted := newJob("https://shop.test/deleted")
+	deleted.NextRunAt = now.Add(-time.Hour)
+	saveJob(t, repo, deleted)
+	require.NoError(t, repo.Delete(allTenants(t), int(deleted.ID)))
+
+	jobs, err := repo.GetDue(allTenants(t), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{earlier.URL, later.URL}, urls(jobs))
+}
+
+func testGetDueLimit(t *testing.T, repo JobRepository) {
+	for i := range 3 {
+		job := newJob(fmt.Sprintf("https://shop.test/%d", i))
+		job.NextRunAt = time.Now().Add(-time.Duration(3-i) * time.Minute)
+		saveJob(t, repo, job)
+	}
+
+	jobs, err := repo.GetDue(allTenants(t), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://shop.test/0", "https://shop.test/1"}, urls(jobs))
+}
+
+func testGetDuePriority(t *testing.T, repo JobRepository) {
+	now := time.Now()
+
+	low := newJob("https://shop.test/low")
+	low.NextRunAt = now.Add(-time.Hour)
+	low.Priority = -5
+	saveJob(t, repo, low)
+
+	normal := newJob("https://shop.test/normal")
+	normal.NextRunAt = now.Add(-time.Hour)
+	saveJob(t, repo, normal)
+
+	high := newJob("https://shop.test/high")
+	high.NextRunAt = now.Add(-time.Minute)
+	high.Priority = 5
+	saveJob(t, repo, high)
+
+	jobs, err := repo.GetDue(allTenants(t), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{high.URL, normal.URL}, urls(jobs))
+
+	stored, err := repo.GetByID(allTenants(t), int(low.ID))
+	require.NoError(t, err)
+	assert.Equal(t, -5, stored.Priority)
+}
+
+func testSaveAll(t *testing.T, repo JobRepository) {
+	a := saveJob(t, repo, newJob("https://shop.test/a"))
+	b := saveJob(t, repo, newJob("https://shop.test/b"))
+
+	a.Status = model.JobStatusInProgress
+	b.Status = model.JobStatusInProgress
+	require.NoError(t, repo.SaveAll(allTenants(t), []*model.Job{a, b}))
+
+	for _, job := range []*model.Job{a, b} {
+		got, err := repo.GetByID(allTenants(t), int(job.ID))
+		require.NoError(t, err)
+		assert.Equal(t, model.JobStatusInProgress, got.Status)
+	}
+}
+
+func testSaveRejectsStaleVersion(t *testing.T, repo JobRepository) {
+	job := saveJob(t, repo, newJob("https://shop.test/a"))
+	assert.EqualValues(t, 1, job.Version)
+
+	stale, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+
+	job.Status = model.JobStatusPaused
+	require.NoError(t, repo.Save(allTenants(t), job))
+	assert.EqualValues(t, 2, job.Version)
+
+	stale.Status = model.JobStatusInProgress
+	err = repo.Save(allTenants(t), stale)
+	assert.ErrorIs(t, err, repository.ErrConflict)
+	assert.EqualValues(t, 1, stale.Version, "version is kept on conflict")
+
+	got, err := repo.GetByID(allTenants(t), int(job.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPaused, got.Status)
+	assert.EqualValues(t, 2, got.Version)
+}
+
+func testSaveAllSkipsConflicts(t *testing.T, repo JobRepository) {
+	a := saveJob(t, repo, newJob("https://shop.test/a"))
+	b := saveJob(t, repo, newJob("https://shop.test/b"))
+
+	// b is paused after the scheduler read it
+	paused, err := repo.GetByID(allTenants(t), int(b.ID))
+	require.NoError(t, err)
+	paused.Status = model.JobStatusPaused
+	require.NoError(t, repo.Save(allTenants(t), paused))
+
+	a.Status = model.JobStatusInProgress
+	b.Status = model.JobStatusInProgress
+	err = repo.SaveAll(allTenants(t), []*model.Job{a, b})
+
+	var conflict *repository.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []uint{b.ID}, conflict.IDs)
+
+	got, err := repo.GetByID(allTenants(t), int(a.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusInProgress, got.Status)
+
+	got, err = repo.GetByID(allTenants(t), int(b.ID))
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPaused, got.Status)
+}
+
+func testURLUniquePerTenant(t *testing.T, repo JobRepository) {
+	a := saveJob(t, repo, newTenantJob("team-a", "https://shop.test/a"))
+	b := saveJob(t, repo, newTenantJob("team-b", "https://shop.test/a"))
+	assert.NotEqual(t, a.ID, b.ID)
+
+	err := repo.Save(allTenants(t), newTenantJob("team-a", "https://shop.test/a"))
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	err = repo.SaveAll(allTenants(t), []*model.Job{newTenantJob("team-c", "https://shop.test/a"), newTenantJob("team-c", "https://shop.test/a")})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+}
+
+func testTenantScope(t *testing.T, repo JobRepository) {
+	a := saveJob(t, repo, newTenantJob("team-a", "https://shop.test/a"))
+	b := saveJob(t, repo, newTenantJob("team-b", "https://shop.test/b"))
+	ctx := repository.WithTenant(t.Context(), "team-a")
+
+	got, err := repo.GetByID(ctx, int(a.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", got.TenantID)
+
+	_, err = repo.GetByID(ctx, int(b.ID))
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repo.GetByURL(ctx, b.URL)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	taken, err := repo.TakenURLs(ctx, []string{a.URL, b.URL})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.URL}, taken)
+
+	jobs, page, err := repo.List(ctx, &model.ListJobsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.URL}, urls(jobs))
+	assert.EqualValues(t, 1, page.Total)
+
+	// the job of another tenant can neither be deleted nor restored
+	require.NoError(t, repo.Delete(ctx, int(b.ID)))
+	_, err = repo.GetByID(allTenants(t), int(b.ID))
+	require.NoError(t, err, "job of another tenant must not be deleted")
+
+	require.NoError(t, repo.Delete(allTenants(t), int(b.ID)))
+	_, err = repo.GetDeleted(ctx, int(b.ID))
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorIs(t, repo.Restore(ctx, int(b.ID)), repository.ErrNotFound)
+
+	// queries allowed to see all tenants do, the tenant of the context takes precedence
+	jobs, _, err = repo.List(allTenants(t), &model.ListJobsFilter{Deleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{b.URL}, urls(jobs))
+
+	jobs, _, err = repo.List(repository.WithTenant(allTenants(t), "team-a"), &model.ListJobsFilter{Deleted: true})
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	// queries neither scoped to a tenant nor allowed to see all tenants fail
+	_, err = repo.GetByID(t.Context(), int(a.ID))
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.GetDeleted(t.Context(), int(b.ID))
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.GetByURL(t.Context(), a.URL)
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.TakenURLs(t.Context(), []string{a.URL})
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, _, err = repo.List(t.Context(), &model.ListJobsFilter{})
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.Count(t.Context())
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	_, err = repo.GetDue(t.Context(), 0)
+	assert.ErrorIs(t, err, repository.ErrNoTenant)
+	assert.ErrorIs(t, repo.Delete(t.Context(), int(a.ID)), repository.ErrNoTenant)
+	assert.ErrorIs(t, repo.Restore(t.Context(), int(b.ID)), repository.ErrNoTenant)
+}
+
+func testCount(t *testing.T, repo JobRepository) {
+	saveJob(t, repo, newTenantJob("team-a", "https://shop.test/a"))
+	saveJob(t, repo, newTenantJob("team-a", "https://shop.test/b"))
+	deleted := saveJob(t, repo, newTenantJob("team-a", "https://shop.test/c"))
+	saveJob(t, repo, newTenantJob("team-b", "https://shop.test/a"))
+	require.NoError(t, repo.Delete(allTenants(t), int(deleted.ID)))
+
+	count, err := repo.Count(repository.WithTenant(t.Context(), "team-a"))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	count, err = repo.Count(allTenants(t))
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+}
@@ -0,0 +1,106 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/retention"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TimeRangeRepositories are the repositories selecting rows by time, created on the same empty database.
+type TimeRangeRepositories struct {
+	Jobs      JobRepository
+	Alerts    service.AlertRepository
+	Audit     service.AuditRepository
+	Retention retention.Repository
+}
+
+// RunTimeRangeContract runs the contract test suite of the time ranges against the repositories created by newRepos.
+// The times are stored with different UTC offsets, the repositories must compare them as instants.
+func RunTimeRangeContract(t *testing.T, newRepos func(t *testing.T) *TimeRangeRepositories) {
+	t.Run("ListEvents filters by instant", func(t *testing.T) { testListEventsTimeRange(t, newRepos(t)) })
+	t.Run("ListEntries filters by instant", func(t *testing.T) { testListEntriesTimeRange(t, newRepos(t)) })
+	t.Run("PurgeDeletedJobs compares instants", func(t *testing.T) { testPurgeDeletedJobsTimeRange(t, newRepos(t)) })
+}
+
+// boundary is the time of the range checks, before and after are on either side of it but
+// compare the other way as text, as they are written with other UTC offsets.
+var (
+	boundary = time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	before   = time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	after    = time.Date(2026, 3, 1, 10, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+)
+
+func testListEventsTimeRange(t *testing.T, repos *TimeRangeRepositories) {
+	for i, triggeredAt := range []time.Time{before, after} {
+		_, err := repos.Alerts.SaveEvent(allTenants(t), &model.AlertEvent{
+			RuleID:        1,
+			ObservationID: uint(i + 1),
+			JobID:         1,
+			Type:          model.AlertRulePriceBelow,
+			Currency:      "EUR",
+			TriggeredAt:   triggeredAt,
+		})
+		require.NoError(t, err)
+	}
+
+	events, _, err := repos.Alerts.ListEvents(allTenants(t), &model.ListAlertsFilter{From: &boundary})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].TriggeredAt.Equal(after))
+
+	events, _, err = repos.Alerts.ListEvents(allTenants(t), &model.ListAlertsFilter{To: &boundary})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].TriggeredAt.Equal(before))
+
+	// newest first
+	events, _, err = repos.Alerts.ListEvents(allTenants(t), &model.ListAlertsFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.True(t, events[0].TriggeredAt.Equal(after))
+}
+
+func testListEntriesTimeRange(t *testing.T, repos *TimeRangeRepositories) {
+	entries := []*model.AuditEntry{
+		{JobID: 1, Action: model.AuditActionCreate, Actor: "before", CreatedAt: before},
+		{JobID: 1, Action: model.AuditActionUpdate, Actor: "after", CreatedAt: after},
+	}
+	require.NoError(t, repos.Audit.AppendEntries(allTenants(t), entries))
+
+	listed, _, err := repos.Audit.ListEntries(allTenants(t), &model.ListAuditFilter{From: &boundary})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "after", listed[0].Actor)
+
+	listed, _, err = repos.Audit.ListEntries(allTenants(t), &model.ListAuditFilter{To: &boundary})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "before", listed[0].Actor)
+}
+
+func testPurgeDeletedJobsTimeRange(t *testing.T, repos *TimeRangeRepositories) {
+	purgedJob := saveJob(t, repos.Jobs, newJob("https://shop.test/purged"))
+	keptJob := saveJob(t, repos.Jobs, newJob("https://shop.test/kept"))
+	for _, job := range []*model.Job{purgedJob, keptJob} {
+		require.NoError(t, repos.Jobs.Delete(allTenants(t), int(job.ID)))
+	}
+	// the time of deletion is set by Delete, it is changed with a save
+	for job, deletedAt := range map[*model.Job]time.Time{purgedJob: before, keptJob: after} {
+		deleted, err := repos.Jobs.GetDeleted(allTenants(t), int(job.ID))
+		require.NoError(t, err)
+		deleted.DeletedAt = &deletedAt
+		require.NoError(t, repos.Jobs.Save(allTenants(t), deleted))
+	}
+
+	purged, err := repos.Retention.PurgeDeletedJobs(allTenants(t), boundary)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = repos.Jobs.GetDeleted(allTenants(t), int(keptJob.ID))
+	assert.NoError(t, err)
+}
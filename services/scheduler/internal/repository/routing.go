@@ -0,0 +1,18 @@
+package repository
+
+import "context"
+
+type replicaReadsKey struct{}
+
+// WithReplicaReads allows the queries made with ctx to be served by a read replica.
+// Replicas may lag behind the primary, so it must only be used for reads that
+// tolerate slightly stale data, never for reads followed by a write.
+func WithReplicaReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaReadsKey{}, true)
+}
+
+// ReplicaReadsAllowed reports whether ctx allows queries to be served by a read replica.
+func ReplicaReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(replicaReadsKey{}).(bool)
+	return allowed
+}
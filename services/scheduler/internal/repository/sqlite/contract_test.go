@@ -0,0 +1,48 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/db"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/postgres"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/repotest"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// openDB returns a migrated, empty database.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := db.Connect(&config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "scheduler.db"),
+	})
+	require.NoError(t, err)
+
+	migrator, err := db.NewMigrator(gormDB)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return gormDB
+}
+
+func TestJobRepositoryContract(t *testing.T) {
+	repotest.RunJobRepositoryContract(t, func(t *testing.T) repotest.JobRepository {
+		return postgres.New(openDB(t), sqlite.Dialect)
+	})
+}
+
+func TestTimeRangeContract(t *testing.T) {
+	repotest.RunTimeRangeContract(t, func(t *testing.T) *repotest.TimeRangeRepositories {
+		gormDB := openDB(t)
+		return &repotest.TimeRangeRepositories{
+			Jobs:      postgres.New(gormDB, sqlite.Dialect),
+			Alerts:    postgres.NewAlertRepository(gormDB, sqlite.Dialect),
+			Audit:     postgres.NewAuditRepository(gormDB, sqlite.Dialect),
+			Retention: postgres.NewRetentionRepository(gormDB, sqlite.Dialect),
+		}
+	})
+}
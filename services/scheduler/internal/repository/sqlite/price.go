@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRepository(db *gorm.DB) *priceRepository {
+	return &priceRepository{db: db}
+}
+
+// dailyPriceRow is a DailyPrice as returned by SQLite, where date() yields text.
+type dailyPriceRow struct {
+	Day      string
+	Min      int64
+	Max      int64
+	Last     int64
+	Currency string
+	Count    int64
+}
+
+func (r *priceRepository) SaveObservation(ctx context.Context, observation *model.PriceObservation) error {
+	return r.db.WithContext(ctx).Create(observation).Error
+}
+
+func (r *priceRepository) LatestObservation(ctx context.Context, jobID uint) (*model.PriceObservation, error) {
+	var observation model.PriceObservation
+	result := r.db.WithContext(ctx).
+		Where("job_id = ?", jobID).
+		Order("julianday(observed_at) DESC, id DESC").
+		Take(&observation)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrNotFound
+	}
+	return &observation, result.Error
+}
+
+func (r *priceRepository) ListObservations(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.PriceObservation, *pagination.Pagination, error) {
+	var observations []*model.PriceObservation
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+	db := r.filterObservations(ctx, jobID, filter)
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	result := db.
+		Order(fmt.Sprintf("julianday(observed_at) %s", sanitizeSortOrder(filter.SortOrder))).
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Find(&observations)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return observations, pagination, nil
+}
+
+// ListDaily downsamples the observations of a job to one row per day (UTC),
+// holding the minimum, maximum and last observed amount of that day.
+func (r *priceRepository) ListDaily(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.DailyPrice, *pagination.Pagination, error) {
+	var rows []*dailyPriceRow
+
+	pagination := pagination.NewPagination(filter.Page, filter.PageSize)
+
+	if err := r.filterObservations(ctx, jobID, filter).
+		Select("COUNT(DISTINCT date(observed_at))").
+		Scan(&pagination.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// SQLite has no ordered aggregates, the last observation of a day is selected by a correlated subquery
+	result := r.filterObservations(ctx, jobID, filter).
+		Select(`date(observed_at) AS day,
+			MIN(amount) AS min,
+			MAX(amount) AS max,
+			(SELECT p.amount FROM price_observations p
+				WHERE p.job_id = price_observations.job_id AND date(p.observed_at) = date(price_observations.observed_at)
+				ORDER BY julianday(p.observed_at) DESC, p.id DESC LIMIT 1) AS last,
+			(SELECT p.currency FROM price_observations p
+				WHERE p.job_id = price_observations.job_id AND date(p.observed_at) = date(price_observations.observed_at)
+				ORDER BY julianday(p.observed_at) DESC, p.id DESC LIMIT 1) AS currency,
+			COUNT(*) AS count`).
+		Group("day").
+		Order(fmt.Sprintf("day %s", sanitizeSortOrder(filter.SortOrder))).
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset()).
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	days := make([]*model.DailyPrice, 0, len(rows))
+	for _, row := range rows {
+		day, err := time.Parse(time.DateOnly, row.Day)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid day %q: %w", row.Day, err)
+		}
+		days = append(days, &model.DailyPrice{
+			Day:      day,
+			Min:      row.Min,
+			Max:      row.Max,
+			Last:     row.Last,
+			Currency: row.Currency,
+			Count:    row.Count,
+		})
+	}
+
+	return days, pagination, nil
+}
+
+func (r *priceRepository) filterObservations(ctx context.Context, jobID uint, filter *model.ListPricesFilter) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(&model.PriceObservation{}).Where("job_id = ?", jobID)
+
+	if filter.From != nil {
+		db = db.Where("julianday(observed_at) >= julianday(?)", *filter.From)
+	}
+
+	if filter.To != nil {
+		db = db.Where("julianday(observed_at) < julianday(?)", *filter.To)
+	}
+
+	return db
+}
+
+// sanitizeSortOrder returns a safe sort direction, defaulting to ascending.
+func sanitizeSortOrder(order *string) string {
+	if order != nil && (*order == "asc" || *order == "desc") {
+		return *order
+	}
+	return "asc"
+}
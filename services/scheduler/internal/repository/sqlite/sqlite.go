@@ -0,0 +1,23 @@
+// Package sqlite adapts the repositories to SQLite for single-node and test deployments.
+//
+// The repositories of the postgres package run on SQLite with Dialect, which holds the
+// SQL that differs between the databases. Only the price repository, which relies on the
+// daily rollups of postgres, has a SQLite counterpart.
+//
+// Timestamps are stored as text including their UTC offset, so all time
+// comparisons go through julianday() to compare instants instead of strings.
+package sqlite
+
+import (
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository/postgres"
+)
+
+// Dialect is the dialect of SQLite.
+var Dialect = postgres.Dialect{
+	Time: func(expr string) string { return "julianday(" + expr + ")" },
+	// the portable equivalent of ILIKE, LOWER only folds ASCII letters
+	URLContains: "LOWER(url) LIKE LOWER(?)",
+	HasTag: func(tag string) (string, any) {
+		return "EXISTS (SELECT 1 FROM json_each(jobs.tags) WHERE json_each.value = ?)", tag
+	},
+}
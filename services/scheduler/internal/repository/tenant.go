@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+type tenantKey struct{}
+
+type allTenantsKey struct{}
+
+// ErrNoTenant is returned by the queries made with a context that is neither scoped to a tenant
+// by WithTenant nor allowed to see all tenants by AllTenants.
+var ErrNoTenant = errors.New("query is not scoped to a tenant")
+
+// WithTenant scopes the queries made with ctx to the jobs of a tenant, and to the
+// alert rules, alerts, audit entries, webhooks and API keys belonging to them.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// AllTenants allows the queries made with ctx to see the data of all tenants. It is meant for
+// background processes like the scheduler and the purger, requests are scoped by WithTenant.
+// A tenant set by WithTenant takes precedence.
+func AllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+// TenantFromContext returns the tenant ctx is scoped to, and false if ctx is not scoped to a tenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// AllTenantsAllowed reports whether ctx allows queries to see the data of all tenants.
+func AllTenantsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allTenantsKey{}).(bool)
+	return allowed
+}
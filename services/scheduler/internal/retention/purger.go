@@ -0,0 +1,163 @@
+// Package retention periodically purges data that expired its retention period.
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
+)
+
+// Repository permanently removes expired data.
+type Repository interface {
+	// PurgeDeletedJobs removes the jobs soft deleted before deletedBefore and their related data.
+	// It returns the number of purged jobs. Like the scheduler, the purger runs with a context
+	// allowed to see all tenants by repository.AllTenants.
+	PurgeDeletedJobs(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// PartitionRepository manages the time partitioned storage of price observations.
+type PartitionRepository interface {
+	// EnsurePricePartitions creates the partitions from the month of now up to ahead months in advance.
+	// It returns the names of the created partitions.
+	EnsurePricePartitions(ctx context.Context, now time.Time, ahead int) ([]string, error)
+
+	// ExpirePriceObservations drops, or archives if archive is set, the observations made before 'before'.
+	// It returns the names of the expired partitions.
+	ExpirePriceObservations(ctx context.Context, before time.Time, archive bool) ([]string, error)
+}
+
+// Purger removes soft deleted jobs once their retention period expired.
+// With partitioned price storage it also creates upcoming partitions
+// and expires partitions of old price observations.
+type Purger struct {
+	// Repo removes the expired data.
+	Repo Repository
+
+	// Partitions manages the price partitions, nil if the database does not partition prices.
+	Partitions PartitionRepository
+
+	// Interval defines how often expired data is purged.
+	Interval time.Duration
+
+	// Timeout bounds a single purge run.
+	Timeout time.Duration
+
+	// DeletedJobs is the retention period of soft deleted jobs, zero disables purging.
+	DeletedJobs time.Duration
+
+	// PriceObservations is the retention period of price observations, zero keeps them forever.
+	PriceObservations time.Duration
+
+	// ArchivePrices archives expired price partitions instead of dropping them.
+	ArchivePrices bool
+
+	// PartitionsAhead is the number of monthly partitions created in advance.
+	PartitionsAhead int
+}
+
+func NewPurger(cfg *config.RetentionConfig, repo Repository, partitions PartitionRepository) *Purger {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	partitionsAhead := cfg.PartitionsAhead
+	if partitionsAhead <= 0 {
+		partitionsAhead = 3
+	}
+
+	return &Purger{
+		Repo:              repo,
+		Partitions:        partitions,
+		Interval:          interval,
+		Timeout:           timeout,
+		DeletedJobs:       cfg.DeletedJobs,
+		PriceObservations: cfg.PriceObservations,
+		ArchivePrices:     cfg.ArchivePrices,
+		PartitionsAhead:   partitionsAhead,
+	}
+}
+
+// Run purges expired data every interval until ctx is cancelled.
+// Cancelling ctx also aborts a purge in progress.
+func (p *Purger) Run(ctx context.Context) {
+	if p.DeletedJobs <= 0 && p.Partitions == nil {
+		log.Println("[INFO] purger disabled, deleted jobs are kept forever")
+		return
+	}
+	if p.PriceObservations > 0 && p.Partitions == nil {
+		log.Println("[WARN] price retention requires partitioned storage (postgres), price observations are kept forever")
+	}
+
+	log.Printf("purger started: interval=%s, deletedJobs=%s, priceObservations=%s\n", p.Interval, p.DeletedJobs, p.PriceObservations)
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	p.purge(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	if p.Partitions != nil {
+		p.maintainPartitions(ctx)
+	}
+
+	if p.DeletedJobs <= 0 {
+		return
+	}
+
+	purged, err := p.Repo.PurgeDeletedJobs(ctx, time.Now().Add(-p.DeletedJobs))
+	if err != nil {
+		log.Printf("[ERROR] failed to purge deleted jobs: %v\n", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("[INFO] purged %d deleted jobs\n", purged)
+	}
+}
+
+// maintainPartitions creates upcoming price partitions and expires old ones.
+func (p *Purger) maintainPartitions(ctx context.Context) {
+	now := time.Now()
+
+	created, err := p.Partitions.EnsurePricePartitions(ctx, now, p.PartitionsAhead)
+	if err != nil {
+		log.Printf("[ERROR] failed to create price partitions: %v\n", err)
+	}
+	if len(created) > 0 {
+		log.Printf("[INFO] created price partitions: %v\n", created)
+	}
+
+	if p.PriceObservations <= 0 {
+		return
+	}
+
+	expired, err := p.Partitions.ExpirePriceObservations(ctx, now.Add(-p.PriceObservations), p.ArchivePrices)
+	if err != nil {
+		log.Printf("[ERROR] failed to expire price observations: %v\n", err)
+	}
+	if len(expired) > 0 {
+		action := "dropped"
+		if p.ArchivePrices {
+			action = "archived"
+		}
+		log.Printf("[INFO] %s expired price partitions: %v\n", action, expired)
+	}
+}
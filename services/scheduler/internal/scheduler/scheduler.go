@@ -2,12 +2,14 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/config"
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
 )
 
 // Dispatcher handles job submissions to the worker queue.
@@ -15,17 +17,33 @@ import (
 //go:generate mockgen -destination=../../mocks/mock_dispatcher.go -package=mocks github.com/lorenzhoerb/cogniprice/services/scheduler/internal/scheduler Dispatcher
 type Dispatcher interface {
 	// Dispatches all jobs as a batch to the worker queue.
-	DispatchJobs(jobs []model.JobDispatched) error
+	DispatchJobs(ctx context.Context, jobs []model.JobDispatched) error
 }
 
 //go:generate mockgen -destination=../../mocks/scheduler_job_repository.go -package=mocks github.com/lorenzhoerb/cogniprice/services/scheduler/internal/scheduler JobRepository
 type JobRepository interface {
-	// ListDue returns up to 'limit' duo jobs.
-	// If limit == 0, all duo jobs are returned.
-	GetDue(limit int) ([]*model.Job, error)
+	// GetDue returns up to 'limit' due jobs, ordered by priority (highest first) and next run.
+	// If limit == 0, all due jobs are returned. The jobs of all tenants are only returned
+	// if ctx is allowed to see them by repository.AllTenants.
+	GetDue(ctx context.Context, limit int) ([]*model.Job, error)
+}
+
+// RunRepository records the runs of dispatched jobs.
+type RunRepository interface {
+	// StartRuns saves the dispatched jobs and inserts their runs in a single transaction,
+	// runs[i] being the run of jobs[i], and assigns the IDs of the runs.
+	// Jobs modified concurrently are not saved and reported by a *repository.ConflictError.
+	StartRuns(ctx context.Context, jobs []*model.Job, runs []*model.JobRun) error
+}
+
+// Auditor records status changes of dispatched jobs in the audit log.
+type Auditor interface {
+	Record(ctx context.Context, entries ...*model.AuditEntry)
+}
 
-	// UpdateJobs batch updates all jobs specified
-	SaveAll(job []*model.Job) error
+// Publisher publishes the dispatched jobs to the event stream.
+type Publisher interface {
+	Publish(events ...*model.Event)
 }
 
 // Scheduler manages the periodic dispatching of due jobs to the worker queue.
@@ -33,6 +51,15 @@ type Scheduler struct {
 	// Repo provides access to job storage for retrieving and updating job states.
 	Repo JobRepository
 
+	// Runs records a run for every dispatched job.
+	Runs RunRepository
+
+	// Audit records the status change of every dispatched job.
+	Audit Auditor
+
+	// Events publishes every dispatched job.
+	Events Publisher
+
 	// Dispatcher handles the submission of jobs to the worker queue.
 	Dispatcher Dispatcher
 
@@ -41,23 +68,46 @@ type Scheduler struct {
 
 	// BatchSize specifies the maximum number of jobs to schedule in a single run.
 	BatchSize int
+
+	// Timeout bounds the database operations of a single run.
+	Timeout time.Duration
+
+	// DispatchTimeout bounds the submission of a batch to the worker queue.
+	DispatchTimeout time.Duration
 }
 
-func NewScheduler(cfg *config.SchedulerConfig, repo JobRepository, dispatcher Dispatcher) *Scheduler {
+func NewScheduler(cfg *config.SchedulerConfig, repo JobRepository, runs RunRepository, audit Auditor, events Publisher, dispatcher Dispatcher) *Scheduler {
 	batchSize := cfg.BatchSize
 	if batchSize <= 0 {
 		batchSize = 100
 	}
 
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	dispatchTimeout := cfg.DispatchTimeout
+	if dispatchTimeout <= 0 {
+		dispatchTimeout = 10 * time.Second
+	}
+
 	return &Scheduler{
 		Repo:       repo,
+		Runs:       runs,
+		Audit:      audit,
+		Events:     events,
 		Interval:   cfg.Interval,
 		BatchSize:  batchSize,
 		Dispatcher: dispatcher,
+
+		Timeout:         timeout,
+		DispatchTimeout: dispatchTimeout,
 	}
 }
 
-// Start starts the job cycle
+// Run dispatches due jobs every interval until ctx is cancelled.
+// Cancelling ctx also aborts a run in progress.
 func (s *Scheduler) Run(ctx context.Context) {
 	log.Printf("scheduler started: interval=%s, batchSize=%d\n", s.Interval, s.BatchSize)
 	ticker := time.NewTicker(s.Interval)
@@ -67,7 +117,7 @@ func (s *Scheduler) Run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := s.dispatchDueJobs(); err != nil {
+			if err := s.dispatchDueJobs(ctx); err != nil {
 				s.handleDispatchFail()
 			}
 		}
@@ -76,9 +126,12 @@ func (s *Scheduler) Run(ctx context.Context) {
 
 // dispatchDueJobs dispatches jobs due.
 // Upon dispatching it ensures that the job status is set to dispatched.
-func (s *Scheduler) dispatchDueJobs() error {
+func (s *Scheduler) dispatchDueJobs(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
 	log.Println("[INFO] Checking for due jobs...")
-	dueJobs, err := s.Repo.GetDue(s.BatchSize)
+	dueJobs, err := s.Repo.GetDue(ctx, s.BatchSize)
 	if err != nil {
 		return fmt.Errorf("get due jobs failed: %w", err)
 	}
@@ -91,30 +144,73 @@ func (s *Scheduler) dispatchDueJobs() error {
 	log.Printf("[INFO] Found %d jobs due, preparing to dispatch", len(dueJobs))
 	dispatchedAt := time.Now()
 
-	var jobsDispatched []model.JobDispatched
+	before := make(map[uint]model.Job, len(dueJobs))
+	dueRuns := make([]*model.JobRun, 0, len(dueJobs))
 	for _, job := range dueJobs {
+		before[job.ID] = *job
+
 		// set job metadata
 		job.Status = model.JobStatusInProgress
 		job.DispatchedAt = &dispatchedAt
+		dueRuns = append(dueRuns, model.NewJobRun(job, dispatchedAt))
+	}
+
+	// update job metadata and record the runs, their IDs are sent to the workers to report results.
+	// Jobs changed since they were read (e.g. paused) are not dispatched.
+	skipped := map[uint]bool{}
+	if err := s.Runs.StartRuns(ctx, dueJobs, dueRuns); err != nil {
+		var conflict *repository.ConflictError
+		if !errors.As(err, &conflict) {
+			return fmt.Errorf("failed to update job status to DISPATCHED: %w", err)
+		}
+		log.Printf("[INFO] skipping %d jobs modified concurrently: %v\n", len(conflict.IDs), conflict.IDs)
+		for _, id := range conflict.IDs {
+			skipped[id] = true
+		}
+	}
+
+	var runs []*model.JobRun
+	var jobs []*model.Job
+	var entries []*model.AuditEntry
+	actor := model.Actor{Name: model.ActorScheduler}
+	for i, job := range dueJobs {
+		if !skipped[job.ID] {
+			prev := before[job.ID]
+			runs = append(runs, dueRuns[i])
+			jobs = append(jobs, job)
+			entries = append(entries, model.NewAuditEntry(actor, model.AuditActionStatusChange, &prev, job))
+		}
+	}
+
+	if len(runs) == 0 {
+		return nil
+	}
+	s.Audit.Record(ctx, entries...)
 
+	var jobsDispatched []model.JobDispatched
+	for i, job := range jobs {
 		jobsDispatched = append(jobsDispatched, model.JobDispatched{
 			ID:           job.ID,
+			RunID:        runs[i].ID,
 			URL:          job.URL,
 			DispatchedAt: dispatchedAt,
+			Validators:   job.Validators(),
 		})
 	}
 
-	// update job metadata
-	if err := s.Repo.SaveAll(dueJobs); err != nil {
-		return fmt.Errorf("failed to update job status to DISPATCHED: %w", err)
-	}
-
 	// dispatch jobs to worker queue
-	if err := s.Dispatcher.DispatchJobs(jobsDispatched); err != nil {
+	dispatchCtx, cancelDispatch := context.WithTimeout(ctx, s.DispatchTimeout)
+	defer cancelDispatch()
+	if err := s.Dispatcher.DispatchJobs(dispatchCtx, jobsDispatched); err != nil {
 		// TODO: Rollback
 		return fmt.Errorf("failed to dispatch jobs: %w", err)
 	}
 
+	events := make([]*model.Event, 0, len(jobs))
+	for i, job := range jobs {
+		events = append(events, model.NewRunEvent(model.EventJobDispatched, job, runs[i]))
+	}
+	s.Events.Publish(events...)
 	return nil
 }
 
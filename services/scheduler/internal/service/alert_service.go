@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+// AlertRepository defines methods to manage alert rules and the events they trigger.
+type AlertRepository interface {
+	// SaveRule inserts or updates an alert rule.
+	SaveRule(ctx context.Context, rule *model.AlertRule) error
+
+	// GetRuleByID retrieves a rule by its ID.
+	GetRuleByID(ctx context.Context, id int) (*model.AlertRule, error)
+
+	// ListRules lists all rules matching the filter.
+	ListRules(ctx context.Context, filter *model.ListAlertRulesFilter) ([]*model.AlertRule, *pagination.Pagination, error)
+
+	// RulesForJob returns all rules targeting the job directly or via one of its tags.
+	RulesForJob(ctx context.Context, job *model.Job) ([]*model.AlertRule, error)
+
+	// DeleteRule removes a rule by its ID.
+	DeleteRule(ctx context.Context, id int) error
+
+	// SaveEvent inserts an event unless one exists for the same rule and observation.
+	// It reports whether the event was created.
+	SaveEvent(ctx context.Context, event *model.AlertEvent) (bool, error)
+
+	// GetEventByID retrieves an event by its ID.
+	GetEventByID(ctx context.Context, id int) (*model.AlertEvent, error)
+
+	// ListEvents lists all events matching the filter, newest first.
+	ListEvents(ctx context.Context, filter *model.ListAlertsFilter) ([]*model.AlertEvent, *pagination.Pagination, error)
+}
+
+// DeliveryRepository provides read access to the notification delivery log.
+type DeliveryRepository interface {
+	// ListDeliveries lists the deliveries of an alert event to the notification channels.
+	ListDeliveries(ctx context.Context, alertEventID uint, filter *model.ListDeliveriesFilter) ([]*model.NotificationDelivery, *pagination.Pagination, error)
+}
+
+// AlertNotifier sends triggered alerts to the notification channels.
+type AlertNotifier interface {
+	// Notify queues the event for delivery, it must not block.
+	Notify(job *model.Job, event *model.AlertEvent)
+}
+
+type AlertService struct {
+	jobRepo      JobRepository
+	alertRepo    AlertRepository
+	deliveryRepo DeliveryRepository
+	notifier     AlertNotifier
+}
+
+// NewAlertService instantiates an AlertService
+func NewAlertService(jobRepo JobRepository, alertRepo AlertRepository, deliveryRepo DeliveryRepository, notifier AlertNotifier) *AlertService {
+	return &AlertService{
+		jobRepo:      jobRepo,
+		alertRepo:    alertRepo,
+		deliveryRepo: deliveryRepo,
+		notifier:     notifier,
+	}
+}
+
+func (s *AlertService) CreateRule(ctx context.Context, req *model.CreateAlertRuleRequest) (*model.AlertRuleResponse, error) {
+	log.Printf("Creating alert rule of type %s\n", req.Type)
+	if req.JobID != nil {
+		if _, err := s.jobRepo.GetByID(ctx, int(*req.JobID)); err != nil {
+			if err == repository.ErrNotFound {
+				return nil, ErrNotFound(*req.JobID)
+			}
+			return nil, err
+		}
+	}
+
+	rule := &model.AlertRule{
+		TenantID:  tenantOf(ctx),
+		JobID:     req.JobID,
+		Tag:       req.Tag,
+		Type:      req.Type,
+		Threshold: req.Threshold,
+		Percent:   req.Percent,
+	}
+
+	if err := s.alertRepo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return model.ToAlertRuleResponse(rule), nil
+}
+
+func (s *AlertService) GetRule(ctx context.Context, id int) (*model.AlertRuleResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	rule, err := s.getRuleByIDOrNotFound(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToAlertRuleResponse(rule), nil
+}
+
+func (s *AlertService) ListRules(ctx context.Context, filter *model.ListAlertRulesFilter) (*model.PaginatedAlertRulesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	rules, pagination, err := s.alertRepo.ListRules(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.AlertRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		items = append(items, model.ToAlertRuleResponse(rule))
+	}
+
+	return &model.PaginatedAlertRulesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+func (s *AlertService) DeleteRule(ctx context.Context, id int) error {
+	log.Printf("Deleting alert rule with ID: %d\n", id)
+	if _, err := s.getRuleByIDOrNotFound(ctx, id); err != nil {
+		return err
+	}
+	return s.alertRepo.DeleteRule(ctx, id)
+}
+
+func (s *AlertService) ListAlerts(ctx context.Context, filter *model.ListAlertsFilter) (*model.PaginatedAlertsResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List alerts %+v\n", filter)
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, ErrInvalidField("from", "must be before 'to'")
+	}
+
+	events, pagination, err := s.alertRepo.ListEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.AlertEventResponse, 0, len(events))
+	for _, event := range events {
+		items = append(items, model.ToAlertEventResponse(event))
+	}
+
+	return &model.PaginatedAlertsResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// ListDeliveries returns the notification delivery log of an alert event.
+func (s *AlertService) ListDeliveries(ctx context.Context, alertID int, filter *model.ListDeliveriesFilter) (*model.PaginatedDeliveriesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	if _, err := s.alertRepo.GetEventByID(ctx, alertID); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrAlertNotFound(alertID)
+		}
+		return nil, err
+	}
+
+	deliveries, pagination, err := s.deliveryRepo.ListDeliveries(ctx, uint(alertID), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.NotificationDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		items = append(items, model.ToNotificationDeliveryResponse(delivery))
+	}
+
+	return &model.PaginatedDeliveriesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// Evaluate checks all rules of the job against the transition from prev to curr
+// and stores and notifies an event for every rule that fires. Events already stored for the
+// same rule and observation are skipped and not returned.
+func (s *AlertService) Evaluate(ctx context.Context, job *model.Job, prev, curr *model.PriceObservation) ([]*model.AlertEvent, error) {
+	rules, err := s.alertRepo.RulesForJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*model.AlertEvent
+	for _, rule := range rules {
+		if !rule.AppliesTo(job) || !rule.Evaluate(prev, curr) {
+			continue
+		}
+
+		event := model.NewAlertEvent(rule, prev, curr)
+		created, err := s.alertRepo.SaveEvent(ctx, event)
+		if err != nil {
+			return events, err
+		}
+		if created {
+			log.Printf("[INFO] alert rule %d (%s) fired for job %d\n", rule.ID, rule.Type, job.ID)
+			s.notifier.Notify(job, event)
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *AlertService) getRuleByIDOrNotFound(ctx context.Context, id int) (*model.AlertRule, error) {
+	rule, err := s.alertRepo.GetRuleByID(ctx, id)
+	if err == nil {
+		return rule, nil
+	}
+
+	if err == repository.ErrNotFound {
+		return nil, ErrAlertRuleNotFound(id)
+	}
+
+	return nil, err
+}
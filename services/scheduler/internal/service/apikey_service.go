@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+const (
+	// apiKeyPrefix marks the keys of the scheduler, so leaked keys are easy to recognize.
+	apiKeyPrefix = "cp_"
+	// apiKeyBytes is the number of random bytes of a key.
+	apiKeyBytes = 32
+	// apiKeyVisibleChars is the length of the key prefix stored to recognize keys in listings.
+	apiKeyVisibleChars = len(apiKeyPrefix) + 8
+)
+
+// APIKeyRepository defines methods to manage API keys.
+type APIKeyRepository interface {
+	// Save inserts or updates a key.
+	Save(ctx context.Context, key *model.APIKey) error
+
+	// GetByID retrieves a key by its ID.
+	GetByID(ctx context.Context, id int) (*model.APIKey, error)
+
+	// GetActiveByHash retrieves a key that was not revoked by the hash of the key.
+	// Returns repository.ErrNotFound if there is none.
+	GetActiveByHash(ctx context.Context, hash string) (*model.APIKey, error)
+
+	// List lists all keys matching the filter.
+	List(ctx context.Context, filter *model.ListAPIKeysFilter) ([]*model.APIKey, *pagination.Pagination, error)
+
+	// Revoke marks a key as revoked at the given time.
+	Revoke(ctx context.Context, id int, at time.Time) error
+}
+
+type APIKeyService struct {
+	repo APIKeyRepository
+}
+
+// NewAPIKeyService instantiates an APIKeyService
+func NewAPIKeyService(repo APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// CreateKey generates a new random key for the tenant of the caller. Returns ErrForeignTenant
+// if req names another tenant. The key is only returned by this call, just its hash is stored.
+func (s *APIKeyService) CreateKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.CreatedAPIKeyResponse, error) {
+	tenant := tenantOf(ctx)
+	if req.Tenant != "" && req.Tenant != tenant {
+		return nil, ErrForeignTenant
+	}
+
+	log.Printf("Creating api key %q with role %s for tenant %q\n", req.Name, req.Role, tenant)
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(b)
+
+	key := &model.APIKey{
+		Name:     req.Name,
+		Prefix:   secret[:apiKeyVisibleChars],
+		KeyHash:  hashAPIKey(secret),
+		Role:     req.Role,
+		TenantID: tenant,
+	}
+	if err := s.repo.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKeyResponse{
+		APIKeyResponse: *model.ToAPIKeyResponse(key),
+		Key:            secret,
+	}, nil
+}
+
+func (s *APIKeyService) GetKey(ctx context.Context, id int) (*model.APIKeyResponse, error) {
+	key, err := s.getKeyOrNotFound(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToAPIKeyResponse(key), nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, filter *model.ListAPIKeysFilter) (*model.PaginatedAPIKeysResponse, error) {
+	keys, pagination, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, model.ToAPIKeyResponse(key))
+	}
+
+	return &model.PaginatedAPIKeysResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// RevokeKey revokes a key, it is rejected by all further requests.
+// Revoked keys are kept to be listed.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int) error {
+	log.Printf("Revoking api key with ID: %d\n", id)
+	if _, err := s.getKeyOrNotFound(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Revoke(ctx, id, time.Now())
+}
+
+// Authenticate returns the principal of an API key.
+// Returns ErrUnauthorized if the key is unknown or revoked.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*model.Principal, error) {
+	key, err := s.repo.GetActiveByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	return &model.Principal{Subject: "apikey:" + key.Name, Role: key.Role, Tenant: key.TenantID}, nil
+}
+
+func (s *APIKeyService) getKeyOrNotFound(ctx context.Context, id int) (*model.APIKey, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err == nil {
+		return key, nil
+	}
+
+	if err == repository.ErrNotFound {
+		return nil, ErrAPIKeyNotFound(id)
+	}
+
+	return nil, err
+}
+
+// hashAPIKey returns the hex encoded SHA-256 hash of a key. Keys are random with
+// 256 bits of entropy, a fast unsalted hash suffices and allows looking them up by hash.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
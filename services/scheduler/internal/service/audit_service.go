@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+// AuditRepository stores the append-only audit log.
+type AuditRepository interface {
+	// AppendEntries inserts the entries and assigns their IDs.
+	AppendEntries(ctx context.Context, entries []*model.AuditEntry) error
+
+	// ListEntries lists the entries matching the filter, newest first.
+	ListEntries(ctx context.Context, filter *model.ListAuditFilter) ([]*model.AuditEntry, *pagination.Pagination, error)
+}
+
+// Auditor records changes of jobs in the audit log.
+type Auditor interface {
+	// Record appends the entries to the audit log.
+	Record(ctx context.Context, entries ...*model.AuditEntry)
+}
+
+type AuditService struct {
+	repo AuditRepository
+}
+
+// NewAuditService instantiates an AuditService
+func NewAuditService(repo AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record appends the entries to the audit log. Failures are logged but not
+// returned, as the audited changes were already made. For the same reason the
+// entries are recorded even if ctx is cancelled, e.g. by an aborted request.
+func (s *AuditService) Record(ctx context.Context, entries ...*model.AuditEntry) {
+	if err := s.repo.AppendEntries(context.WithoutCancel(ctx), entries); err != nil {
+		log.Printf("[ERROR] failed to record %d audit entries: %v\n", len(entries), err)
+	}
+}
+
+// ListEntries returns the audit log filtered by job, actor, action and time range.
+func (s *AuditService) ListEntries(ctx context.Context, filter *model.ListAuditFilter) (*model.PaginatedAuditResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List audit entries %+v\n", filter)
+
+	entries, pagination, err := s.repo.ListEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, model.ToAuditEntryResponse(entry))
+	}
+
+	return &model.PaginatedAuditResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
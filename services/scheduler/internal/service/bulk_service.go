@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+// JobUpdater applies the actions of bulk operations to single jobs.
+type JobUpdater interface {
+	PauseJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) (*model.JobResponse, error)
+	ResumeJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) (*model.JobResponse, error)
+	DeleteJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) error
+}
+
+// operationRetention is how long finished operations can be retrieved.
+const operationRetention = time.Hour
+
+type BulkService struct {
+	repo JobRepository
+	jobs JobUpdater
+
+	mu         sync.Mutex
+	operations map[string]*model.Operation
+}
+
+// NewBulkService instantiates a BulkService.
+// The actions are applied to the jobs one by one by jobs, so they are audited like single changes.
+func NewBulkService(repo JobRepository, jobs JobUpdater) *BulkService {
+	return &BulkService{
+		repo:       repo,
+		jobs:       jobs,
+		operations: map[string]*model.Operation{},
+	}
+}
+
+// StartOperation applies the action of req to the selected jobs in the background.
+// The jobs are selected before the operation starts, so exactly the confirmed jobs are changed
+// even if jobs matching the selector are created or changed in the meantime.
+// It fails with ErrConfirmCountMismatch if req.ConfirmCount is not the number of selected jobs.
+// The progress is reported by GetOperation until operationRetention after the operation finished.
+func (s *BulkService) StartOperation(ctx context.Context, actor model.Actor, req *model.BulkJobsRequest) (*model.OperationResponse, error) {
+	filter := &model.ListJobsFilter{URL: req.URL, Status: req.Status, Tag: req.Tag}
+	ids, err := s.selectJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != *req.ConfirmCount {
+		return nil, ErrConfirmCountMismatch(int64(len(ids)), *req.ConfirmCount)
+	}
+
+	op := &model.Operation{
+		ID:        newOperationID(),
+		Action:    req.Action,
+		Status:    model.OperationStatusRunning,
+		TenantID:  tenantOf(ctx),
+		Total:     len(ids),
+		CreatedAt: time.Now(),
+	}
+	log.Printf("Starting operation %s: %s %d jobs matching %+v\n", op.ID, op.Action, op.Total, filter)
+
+	s.mu.Lock()
+	s.removeExpired(op.CreatedAt)
+	s.operations[op.ID] = op
+	resp := model.ToOperationResponse(op)
+	s.mu.Unlock()
+
+	// the operation outlives the request, but keeps its values (e.g. the request ID)
+	go s.run(context.WithoutCancel(ctx), actor, op, ids)
+	return resp, nil
+}
+
+// GetOperation returns the progress of an operation of the tenant of ctx.
+func (s *BulkService) GetOperation(ctx context.Context, id string) (*model.OperationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op, ok := s.operations[id]
+	if !ok || op.TenantID != tenantOf(ctx) {
+		return nil, ErrOperationNotFound(id)
+	}
+	return model.ToOperationResponse(op), nil
+}
+
+// run applies the action of op to the jobs with the IDs selected when the operation started.
+func (s *BulkService) run(ctx context.Context, actor model.Actor, op *model.Operation, ids []uint) {
+	for _, id := range ids {
+		err := s.apply(ctx, actor, op.Action, id)
+		s.update(op, func(op *model.Operation) {
+			if err != nil {
+				op.Fail(id, err)
+			} else {
+				op.Succeed()
+			}
+		})
+	}
+
+	s.update(op, func(op *model.Operation) { op.Finish(nil) })
+	log.Printf("[INFO] operation %s completed: %d succeeded, %d failed\n", op.ID, op.Succeeded, op.Failed)
+}
+
+// selectJobs returns the IDs of the jobs matching filter, oldest first.
+// All jobs are selected before they are changed, so an action does not change the selection while paging.
+func (s *BulkService) selectJobs(ctx context.Context, filter *model.ListJobsFilter) ([]uint, error) {
+	sortBy, sortOrder := "created_at", "asc"
+	page := *filter
+	page.SortBy = &sortBy
+	page.SortOrder = &sortOrder
+	page.PageSize = pagination.MAX_PAGE_SIZE
+
+	var ids []uint
+	for page.Page = 1; ; page.Page++ {
+		jobs, p, err := s.repo.List(ctx, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+		if page.Page >= p.TotalPages() {
+			return ids, nil
+		}
+	}
+}
+
+func (s *BulkService) apply(ctx context.Context, actor model.Actor, action model.BulkAction, id uint) error {
+	switch action {
+	case model.BulkActionPause:
+		_, err := s.jobs.PauseJob(ctx, actor, int(id), nil)
+		return err
+	case model.BulkActionResume:
+		_, err := s.jobs.ResumeJob(ctx, actor, int(id), nil)
+		return err
+	case model.BulkActionDelete:
+		return s.jobs.DeleteJob(ctx, actor, int(id), nil)
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+func (s *BulkService) update(op *model.Operation, update func(op *model.Operation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(op)
+}
+
+// removeExpired removes the operations finished operationRetention before now.
+// The caller must hold the lock.
+func (s *BulkService) removeExpired(now time.Time) {
+	for id, op := range s.operations {
+		if op.IsFinished() && now.Sub(*op.FinishedAt) > operationRetention {
+			delete(s.operations, id)
+		}
+	}
+}
+
+func newOperationID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
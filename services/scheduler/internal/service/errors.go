@@ -30,6 +30,56 @@ var (
 		Code:    "CANNOT_PAUSE_JOB",
 		Status:  400,
 	}
+	ErrRunFinished = &AppError{
+		Message: "the result of the run was already reported",
+		Code:    "RUN_FINISHED",
+		Status:  409,
+	}
+	ErrRestoreURLTaken = &AppError{
+		Message: "another job uses the URL of the deleted job, delete it or change its URL before restoring",
+		Code:    "JOB_WITH_URL_EXISTS",
+		Status:  409,
+	}
+	ErrJobNotDeleted = &AppError{
+		Message: "job is not deleted",
+		Code:    "JOB_NOT_DELETED",
+		Status:  409,
+	}
+	ErrJobModified = &AppError{
+		Message: "job was modified concurrently, please retry",
+		Code:    "JOB_MODIFIED",
+		Status:  409,
+	}
+	ErrJobInProgress = &AppError{
+		Message: "job is in progress, wait for its run to finish",
+		Code:    "JOB_IN_PROGRESS",
+		Status:  409,
+	}
+	ErrDispatchFailed = &AppError{
+		Message: "failed to submit the jobs to the worker queue",
+		Code:    "DISPATCH_FAILED",
+		Status:  503,
+	}
+	ErrPreconditionFailed = &AppError{
+		Message: "job version does not match If-Match",
+		Code:    "PRECONDITION_FAILED",
+		Status:  412,
+	}
+	ErrUnauthorized = &AppError{
+		Message: "missing or invalid credentials",
+		Code:    "UNAUTHORIZED",
+		Status:  401,
+	}
+	ErrForbidden = &AppError{
+		Message: "the role of the credentials does not allow this request",
+		Code:    "FORBIDDEN",
+		Status:  403,
+	}
+	ErrForeignTenant = &AppError{
+		Message: "api keys can only be issued for the tenant of the credentials",
+		Code:    "FORBIDDEN",
+		Status:  403,
+	}
 )
 
 func ErrNotFound(id any) *AppError {
@@ -40,6 +90,70 @@ func ErrNotFound(id any) *AppError {
 	}
 }
 
+func ErrRunNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("run with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrAlertRuleNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("alert rule with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrAlertNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("alert with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrWebhookNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("webhook with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrAPIKeyNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("api key with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrOperationNotFound(id any) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("operation with id %v not found", id),
+		Code:    "NOT_FOUND",
+		Status:  404,
+	}
+}
+
+func ErrConfirmCountMismatch(selected int64, confirmed int) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("the selector matches %d jobs, but confirmCount is %d", selected, confirmed),
+		Code:    "CONFIRM_COUNT_MISMATCH",
+		Status:  409,
+	}
+}
+
+func ErrJobQuotaExceeded(maxJobs int) *AppError {
+	return &AppError{
+		Message: fmt.Sprintf("the job quota of the tenant is exceeded, it can have at most %d jobs", maxJobs),
+		Code:    "QUOTA_EXCEEDED",
+		Status:  403,
+	}
+}
+
 func ErrInvalidField(field, msg string) *AppError {
 	return &AppError{
 		Message: fmt.Sprintf("invalid value for field '%s'", field),
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"log"
+	"sync"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+)
+
+// Publisher publishes changes in the lifecycle of jobs.
+type Publisher interface {
+	// Publish assigns the events their IDs and passes them to the subscribers.
+	Publish(events ...*model.Event)
+}
+
+const (
+	// defaultEventBufferSize is the number of recent events kept if no buffer size is configured.
+	defaultEventBufferSize = 1000
+
+	// subscriptionBufferSize is the number of events queued for a subscriber,
+	// subscribers falling further behind are dropped.
+	subscriptionBufferSize = 100
+)
+
+// Subscription receives the published events matching its filter.
+type Subscription struct {
+	filter model.EventFilter
+	events chan *model.Event
+}
+
+// Events returns the channel of the subscribed events. It is closed when the subscription
+// is cancelled, or when the subscriber fell behind and events had to be dropped.
+func (s *Subscription) Events() <-chan *model.Event {
+	return s.events
+}
+
+// EventService passes the events of the job lifecycle to the subscribers of the event stream
+// and forwards them to its sinks, e.g. the webhook deliverer.
+// The most recent events are kept in a ring buffer, so subscribers can resume after reconnecting.
+type EventService struct {
+	mu     sync.Mutex
+	lastID uint64
+	// buffer holds the event with ID n at index (n-1) % len(buffer)
+	buffer      []*model.Event
+	subscribers map[*Subscription]struct{}
+	sinks       []Publisher
+}
+
+// NewEventService instantiates an EventService keeping the bufferSize most recent events.
+// The published events are forwarded to the sinks once their IDs are assigned, sinks must not block.
+func NewEventService(bufferSize int, sinks ...Publisher) *EventService {
+	if bufferSize <= 0 {
+		bufferSize = defaultEventBufferSize
+	}
+	return &EventService{
+		buffer:      make([]*model.Event, bufferSize),
+		subscribers: map[*Subscription]struct{}{},
+		sinks:       sinks,
+	}
+}
+
+// Publish assigns the events their IDs and passes them to the subscribers and sinks.
+// It never blocks, subscribers whose queue is full are dropped.
+func (s *EventService) Publish(events ...*model.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		s.lastID++
+		event.ID = s.lastID
+		s.buffer[s.index(event.ID)] = event
+
+		for sub := range s.subscribers {
+			if !sub.filter.Matches(event) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				log.Printf("[WARN] dropping event subscriber falling behind at event %d\n", event.ID)
+				s.remove(sub)
+			}
+		}
+	}
+
+	for _, sink := range s.sinks {
+		sink.Publish(events...)
+	}
+}
+
+// Subscribe returns a subscription to the events matching filter.
+// If lastEventID is not nil, the buffered events after it are returned to be sent first.
+// It reports false if events after lastEventID are no longer buffered or were never published,
+// e.g. because of a restart, nothing is replayed then.
+func (s *EventService) Subscribe(filter *model.EventFilter, lastEventID *uint64) (*Subscription, []*model.Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &Subscription{filter: *filter, events: make(chan *model.Event, subscriptionBufferSize)}
+	s.subscribers[sub] = struct{}{}
+
+	if lastEventID == nil {
+		return sub, nil, true
+	}
+	if *lastEventID > s.lastID || *lastEventID+1 < s.oldestID() {
+		return sub, nil, false
+	}
+
+	var replay []*model.Event
+	for id := *lastEventID + 1; id <= s.lastID; id++ {
+		if event := s.buffer[s.index(id)]; filter.Matches(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, true
+}
+
+// Unsubscribe cancels the subscription and closes its channel.
+func (s *EventService) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+// remove closes the channel of a subscription if it is still subscribed.
+// The caller must hold the lock.
+func (s *EventService) remove(sub *Subscription) {
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// oldestID returns the ID of the oldest buffered event. The caller must hold the lock.
+func (s *EventService) oldestID() uint64 {
+	size := uint64(len(s.buffer))
+	if s.lastID < size {
+		return 1
+	}
+	return s.lastID - size + 1
+}
+
+func (s *EventService) index(id uint64) int {
+	return int((id - 1) % uint64(len(s.buffer)))
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+)
+
+// importBatchSize is the number of rows imported in a single transaction.
+const importBatchSize = 500
+
+// ImportJobs creates a job for every valid row whose URL is not used by a job or an earlier row.
+// Rows are imported in batches, each in a single transaction, so a failing import keeps the
+// batches already imported. In a dry run the rows are only checked and nothing is created.
+// Rows exceeding the job quota of the tenant are invalid.
+func (s *JobService) ImportJobs(ctx context.Context, actor model.Actor, rows []*model.ImportJobRow, dryRun bool) (*model.ImportJobsResponse, error) {
+	log.Printf("Importing %d jobs (dry run: %t)\n", len(rows), dryRun)
+	tenant := tenantOf(ctx)
+	quota := s.quotas.For(tenant)
+	remaining, err := s.remainingJobs(ctx, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &jobImport{
+		actor:     actor,
+		tenant:    tenant,
+		quota:     quota,
+		remaining: remaining,
+		seen:      map[string]int{},
+		dryRun:    dryRun,
+	}
+	resp := &model.ImportJobsResponse{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]*model.ImportRowResult, 0, len(rows)),
+	}
+
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := min(start+importBatchSize, len(rows))
+		results, err := s.importBatch(ctx, imp, rows[start:end])
+		if err != nil {
+			return nil, err
+		}
+		resp.Rows = append(resp.Rows, results...)
+	}
+
+	for _, row := range resp.Rows {
+		switch row.Status {
+		case model.ImportRowCreated, model.ImportRowValid:
+			resp.Created++
+		case model.ImportRowDuplicate:
+			resp.Duplicates++
+		case model.ImportRowInvalid:
+			resp.Invalid++
+		}
+	}
+	return resp, nil
+}
+
+// jobImport is the state of an import shared by its batches.
+type jobImport struct {
+	actor  model.Actor
+	tenant string
+	quota  model.TenantQuota
+	// remaining is the number of jobs the tenant can still create
+	remaining int
+	// lines of the rows by URL, to detect duplicates within the file
+	seen   map[string]int
+	dryRun bool
+}
+
+// importBatch validates the rows and creates the jobs of the valid rows in a single transaction.
+func (s *JobService) importBatch(ctx context.Context, imp *jobImport, rows []*model.ImportJobRow) ([]*model.ImportRowResult, error) {
+	results := make([]*model.ImportRowResult, len(rows))
+	jobs := make([]*model.Job, len(rows))
+	var urls []string
+	for i, row := range rows {
+		result := &model.ImportRowResult{Line: row.Line}
+		results[i] = result
+		if row.Job != nil {
+			result.URL = row.Job.URL
+		}
+
+		if len(row.Errors) > 0 {
+			result.Status = model.ImportRowInvalid
+			result.Errors = row.Errors
+			continue
+		}
+
+		job, err := newJob(row.Job, imp.tenant, imp.quota)
+		if err != nil {
+			result.Status = model.ImportRowInvalid
+			result.Errors = importErrors(err)
+			continue
+		}
+
+		if line, ok := imp.seen[job.URL]; ok {
+			result.Status = model.ImportRowDuplicate
+			result.Errors = []model.ImportError{{Field: "url", Message: fmt.Sprintf("already used by line %d", line)}}
+			continue
+		}
+		imp.seen[job.URL] = row.Line
+		jobs[i] = job
+		urls = append(urls, job.URL)
+	}
+
+	taken, err := s.repo.TakenURLs(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+	isTaken := make(map[string]bool, len(taken))
+	for _, url := range taken {
+		isTaken[url] = true
+	}
+
+	var created []*model.Job
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if isTaken[job.URL] {
+			markDuplicate(results[i])
+			jobs[i] = nil
+			continue
+		}
+		if imp.remaining == 0 {
+			results[i].Status = model.ImportRowInvalid
+			results[i].Errors = []model.ImportError{{Message: ErrJobQuotaExceeded(imp.quota.MaxJobs).Message}}
+			jobs[i] = nil
+			continue
+		}
+		imp.remaining--
+		created = append(created, job)
+	}
+
+	if imp.dryRun {
+		for i, job := range jobs {
+			if job != nil {
+				results[i].Status = model.ImportRowValid
+			}
+		}
+		return results, nil
+	}
+
+	if err := s.createAll(ctx, created); err != nil {
+		return nil, err
+	}
+
+	// the jobs are created, their history is recorded even if the request is aborted
+	ctx = context.WithoutCancel(ctx)
+
+	var entries []*model.AuditEntry
+	var events []*model.Event
+	for i, job := range jobs {
+		if job == nil {
+			continue
+		}
+		if job.ID == 0 {
+			// URL was taken concurrently
+			markDuplicate(results[i])
+			continue
+		}
+		id := job.ID
+		results[i].Status = model.ImportRowCreated
+		results[i].JobID = &id
+		entries = append(entries, model.NewAuditEntry(imp.actor, model.AuditActionCreate, nil, job))
+		events = append(events, model.NewJobEvent(model.EventJobCreated, job))
+	}
+	if len(entries) > 0 {
+		s.audit.Record(ctx, entries...)
+		s.events.Publish(events...)
+	}
+	return results, nil
+}
+
+// createAll creates the jobs in a single transaction. If a URL was taken concurrently,
+// the jobs are created one by one and the jobs with a taken URL are left without an ID.
+func (s *JobService) createAll(ctx context.Context, jobs []*model.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	err := s.repo.SaveAll(ctx, jobs)
+	if !errors.Is(err, repository.ErrDuplicate) {
+		return err
+	}
+
+	log.Printf("[INFO] URLs of imported jobs were taken concurrently, creating %d jobs one by one\n", len(jobs))
+	for _, job := range jobs {
+		job.ID = 0
+		err := s.repo.Save(ctx, job)
+		if errors.Is(err, repository.ErrDuplicate) {
+			job.ID = 0
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markDuplicate(result *model.ImportRowResult) {
+	result.Status = model.ImportRowDuplicate
+	result.Errors = []model.ImportError{{Field: "url", Message: ErrJobWithURLExists.Message}}
+}
+
+// importErrors returns the field errors of err as errors of an imported row.
+func importErrors(err error) []model.ImportError {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		return []model.ImportError{{Message: err.Error()}}
+	}
+
+	if len(appErr.Errors) == 0 {
+		return []model.ImportError{{Message: appErr.Message}}
+	}
+	errs := make([]model.ImportError, 0, len(appErr.Errors))
+	for _, fe := range appErr.Errors {
+		errs = append(errs, model.ImportError{Field: fe.Field, Message: fe.Message})
+	}
+	return errs
+}
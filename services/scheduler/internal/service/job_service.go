@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"time"
 
 	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
@@ -11,6 +14,7 @@ import (
 )
 
 // JobRepository defines methods to manage jobs in the scheduler service.
+// All queries are restricted to the jobs of the tenant the context is scoped to by repository.WithTenant.
 type JobRepository interface {
 	// ListDuoJobs returns up to 'limit' duo jobs.
 	// If limit == 0, all duo jobs are returned.
@@ -18,40 +22,104 @@ type JobRepository interface {
 
 	// GetJob retrieves a job by its ID.
 	//GetJob(id int) (*model.Job, error)
-	GetByID(id int) (*model.Job, error)
+	GetByID(ctx context.Context, id int) (*model.Job, error)
 
 	// GetByURL retrieves a job by its URL.
 	// Returns null if not found.
-	GetByURL(url string) (*model.Job, error)
+	GetByURL(ctx context.Context, url string) (*model.Job, error)
+
+	// TakenURLs returns the URLs of urls used by a job, soft deleted jobs are ignored.
+	TakenURLs(ctx context.Context, urls []string) ([]string, error)
 
 	// List all jobs and filters them
-	List(filter *model.ListJobsFilter) ([]*model.Job, *pagination.Pagination, error)
+	List(ctx context.Context, filter *model.ListJobsFilter) ([]*model.Job, *pagination.Pagination, error)
+
+	// Count returns the number of jobs, soft deleted jobs are not counted.
+	Count(ctx context.Context) (int64, error)
 
-	// PutJob inserts or updates a job.
+	// Save inserts or updates a job.
 	// If job.ID is empty, an ID is generated and assigned to the same object.
-	Save(job *model.Job) error
+	// Updates only succeed if job.Version matches the stored version, the version is incremented on success.
+	// Returns repository.ErrDuplicate if another job of the same tenant has the URL,
+	// and a *repository.ConflictError if the job was modified concurrently.
+	Save(ctx context.Context, job *model.Job) error
+
+	// SaveAll saves all jobs in a single transaction like Save.
+	// Returns repository.ErrDuplicate if any of the jobs uses the URL of another job, none of the jobs are saved then.
+	SaveAll(ctx context.Context, jobs []*model.Job) error
+
+	// Delete soft deletes a job by its ID and increments its version.
+	// Deleted jobs are excluded from all queries unless listed with ListJobsFilter.Deleted.
+	Delete(ctx context.Context, id int) error
+
+	// GetDeleted retrieves a soft deleted job by its ID.
+	// Returns repository.ErrNotFound if there is no deleted job with the ID.
+	GetDeleted(ctx context.Context, id int) (*model.Job, error)
+
+	// Restore undoes the soft delete of a job and increments its version.
+	// Returns repository.ErrNotFound if there is no deleted job with the ID,
+	// and repository.ErrDuplicate if another job of the same tenant has its URL.
+	Restore(ctx context.Context, id int) error
+}
+
+// IntervalChangeRepository stores the interval changes of jobs for auditing.
+type IntervalChangeRepository interface {
+	// SaveIntervalChange inserts a new interval change.
+	SaveIntervalChange(ctx context.Context, change *model.IntervalChange) error
+
+	// ListIntervalChanges lists the interval changes of a job, newest first.
+	ListIntervalChanges(ctx context.Context, jobID uint, filter *model.ListIntervalChangesFilter) ([]*model.IntervalChange, *pagination.Pagination, error)
+}
+
+// RunRepository stores the execution history of jobs.
+type RunRepository interface {
+	// SaveRun inserts or updates a run.
+	SaveRun(ctx context.Context, run *model.JobRun) error
+
+	// GetRun retrieves a run by its ID.
+	// Returns repository.ErrNotFound if it does not exist.
+	GetRun(ctx context.Context, id uint) (*model.JobRun, error)
 
-	// Delete removes a job by its ID.
-	Delete(id int) error
+	// LatestOpenRun returns the most recently dispatched unfinished run of a job.
+	// Returns repository.ErrNotFound if the job has no unfinished run.
+	LatestOpenRun(ctx context.Context, jobID uint) (*model.JobRun, error)
+
+	// ListRuns lists the runs of a job, newest first.
+	ListRuns(ctx context.Context, jobID uint, filter *model.ListRunsFilter) ([]*model.JobRun, *pagination.Pagination, error)
 }
 
+// maxConflictRetries is how often a read-modify-write of a job is attempted
+// if the job is modified concurrently and the client did not expect a version.
+const maxConflictRetries = 3
+
 type JobService struct {
-	repo JobRepository
+	repo         JobRepository
+	intervalRepo IntervalChangeRepository
+	runRepo      RunRepository
+	audit        Auditor
+	events       Publisher
+	quotas       *model.TenantQuotas
 }
 
-// NewJobService instantiates a JobService
-func NewJobService(repo JobRepository) *JobService {
+// NewJobService instantiates a JobService.
+// All changes of jobs are recorded by audit, created and paused jobs are published to events.
+// Jobs are created for the tenant of the request context, limited by the quota of the tenant.
+func NewJobService(repo JobRepository, intervalRepo IntervalChangeRepository, runRepo RunRepository, audit Auditor, events Publisher, quotas *model.TenantQuotas) *JobService {
 	return &JobService{
-		repo: repo,
+		repo:         repo,
+		intervalRepo: intervalRepo,
+		runRepo:      runRepo,
+		audit:        audit,
+		events:       events,
+		quotas:       quotas,
 	}
 }
 
-func (s *JobService) CreateJob(req *model.CreateJobRequest) (*model.JobResponse, error) {
+func (s *JobService) CreateJob(ctx context.Context, actor model.Actor, req *model.CreateJobRequest) (*model.JobResponse, error) {
 	log.Printf("Creating job with URL: %s and Interval: %s\n", req.URL, req.Interval)
-	interval, _ := time.ParseDuration(req.Interval) // already validated
 
 	// Check for existing job with the same URL
-	_, err := s.repo.GetByURL(req.URL)
+	_, err := s.repo.GetByURL(ctx, req.URL)
 	if err == nil {
 		// Job exists → cannot create duplicate
 		return nil, ErrJobWithURLExists
@@ -61,24 +129,76 @@ func (s *JobService) CreateJob(req *model.CreateJobRequest) (*model.JobResponse,
 		return nil, err
 	}
 
+	tenant := tenantOf(ctx)
+	quota := s.quotas.For(tenant)
+	job, err := newJob(req, tenant, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkJobQuota(ctx, quota, 1); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Save(ctx, job)
+	if errors.Is(err, repository.ErrDuplicate) {
+		// Job with the same URL was created concurrently
+		return nil, ErrJobWithURLExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionCreate, nil, job))
+	s.events.Publish(model.NewJobEvent(model.EventJobCreated, job))
+	return model.ToJobResponse(job), nil
+}
+
+// newJob returns the scheduled job of the tenant created by req, which passed the binding validation.
+// It returns an *AppError if the adaptive bounds do not contain the interval, or an interval
+// is shorter than the minimum interval of the quota.
+func newJob(req *model.CreateJobRequest, tenant string, quota model.TenantQuota) (*model.Job, error) {
+	interval, _ := time.ParseDuration(req.Interval) // already validated
+	if err := checkMinInterval("interval", interval, quota); err != nil {
+		return nil, err
+	}
+
 	job := &model.Job{
+		TenantID:  tenant,
 		URL:       req.URL,
 		Interval:  interval,
+		Tags:      req.Tags,
+		Priority:  model.DefaultPriority,
 		Status:    model.JobStatusScheduled,
 		NextRunAt: time.Now(),
 	}
-
-	err = s.repo.Save(job)
-	if err != nil {
-		return nil, err
+	if req.Priority != nil {
+		job.Priority = *req.Priority
 	}
 
-	return model.ToJobResponse(job), nil
+	if req.Adaptive {
+		minInterval, _ := time.ParseDuration(req.MinInterval) // already validated
+		maxInterval, _ := time.ParseDuration(req.MaxInterval) // already validated
+		if minInterval > interval {
+			return nil, ErrInvalidField("minInterval", "must not be greater than interval")
+		}
+		if maxInterval < interval {
+			return nil, ErrInvalidField("maxInterval", "must not be less than interval")
+		}
+		if err := checkMinInterval("minInterval", minInterval, quota); err != nil {
+			return nil, err
+		}
+		job.Adaptive = true
+		job.MinInterval = minInterval
+		job.MaxInterval = maxInterval
+	}
+	return job, nil
 }
 
-func (s *JobService) GetJob(id int) (*model.JobResponse, error) {
+func (s *JobService) GetJob(ctx context.Context, id int) (*model.JobResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
 	log.Printf("Retrieving job with ID: %d\n", id)
-	job, err := s.getJobByIDOrNotFound(id)
+	job, err := s.getJobByIDOrNotFound(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -86,10 +206,11 @@ func (s *JobService) GetJob(id int) (*model.JobResponse, error) {
 	return model.ToJobResponse(job), nil
 }
 
-func (s *JobService) ListJobs(filter *model.ListJobsFilter) (*model.PaginatedJobsResponse, error) {
+func (s *JobService) ListJobs(ctx context.Context, filter *model.ListJobsFilter) (*model.PaginatedJobsResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
 	log.Printf("List jobs %+v\n", filter)
 
-	jobs, pagination, err := s.repo.List(filter)
+	jobs, pagination, err := s.repo.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -109,45 +230,293 @@ func (s *JobService) ListJobs(filter *model.ListJobsFilter) (*model.PaginatedJob
 	}, nil
 }
 
-func (s *JobService) PauseJob(id int) (*model.JobResponse, error) {
-	log.Printf("Pausing job with ID: %d\n", id)
-	job, err := s.getJobByIDOrNotFound(id)
+// ExportJobs passes every job matching filter to write, ignoring the pagination of filter.
+// The jobs are read page by page, so only a single page is held in memory.
+// The export stops at the first error returned by write.
+func (s *JobService) ExportJobs(ctx context.Context, filter *model.ListJobsFilter, write func(*model.JobResponse) error) error {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("Export jobs %+v\n", filter)
+
+	page := *filter
+	page.PageSize = pagination.MAX_PAGE_SIZE
+	for page.Page = 1; ; page.Page++ {
+		jobs, p, err := s.repo.List(ctx, &page)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if err := write(model.ToJobResponse(job)); err != nil {
+				return err
+			}
+		}
+		if page.Page >= p.TotalPages() {
+			return nil
+		}
+	}
+}
+
+// ListIntervalChanges returns the audit history of interval changes of a job.
+func (s *JobService) ListIntervalChanges(ctx context.Context, id int, filter *model.ListIntervalChangesFilter) (*model.PaginatedIntervalChangesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List interval changes of job %d\n", id)
+	if _, err := s.getJobByIDOrNotFound(ctx, id); err != nil {
+		return nil, err
+	}
+
+	changes, pagination, err := s.intervalRepo.ListIntervalChanges(ctx, uint(id), filter)
 	if err != nil {
 		return nil, err
 	}
 
-	err = job.Pause()
+	items := make([]*model.IntervalChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, model.ToIntervalChangeResponse(change))
+	}
+
+	return &model.PaginatedIntervalChangesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// ListRuns returns the execution history of a job, newest first.
+func (s *JobService) ListRuns(ctx context.Context, id int, filter *model.ListRunsFilter) (*model.PaginatedRunsResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List runs of job %d\n", id)
+	if _, err := s.getJobByIDOrNotFound(ctx, id); err != nil {
+		return nil, err
+	}
+
+	runs, pagination, err := s.runRepo.ListRuns(ctx, uint(id), filter)
 	if err != nil {
-		return nil, ErrCannotPauseJob
+		return nil, err
 	}
 
-	err = s.repo.Save(job)
+	items := make([]*model.JobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, model.ToJobRunResponse(run))
+	}
+
+	return &model.PaginatedRunsResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// PauseJob pauses the job. The job is only paused if it satisfies ifMatch.
+func (s *JobService) PauseJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) (*model.JobResponse, error) {
+	log.Printf("Pausing job with ID: %d\n", id)
+	before, job, err := s.updateJob(ctx, id, ifMatch, func(job *model.Job) error {
+		if err := job.Pause(); err != nil {
+			return ErrCannotPauseJob
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionPause, before, job))
+	if before.Status != model.JobStatusPaused {
+		s.events.Publish(model.NewJobEvent(model.EventJobPaused, job))
+	}
 	return model.ToJobResponse(job), nil
 }
 
-func (s *JobService) ResumeJob(id int) (*model.JobResponse, error) {
+// ResumeJob resumes the job. The job is only resumed if it satisfies ifMatch.
+func (s *JobService) ResumeJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) (*model.JobResponse, error) {
 	log.Printf("Resuming job with ID: %d\n", id)
-	job, err := s.getJobByIDOrNotFound(id)
+	before, job, err := s.updateJob(ctx, id, ifMatch, func(job *model.Job) error {
+		return job.Resume()
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	job.Resume()
+	s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionResume, before, job))
+	return model.ToJobResponse(job), nil
+}
 
-	err = s.repo.Save(job)
+// UpdateJob applies the merge patch req to the job. The job is only updated if it satisfies ifMatch.
+// A changed interval is recorded as a manual interval change, a changed URL resets the validators of the last crawl.
+func (s *JobService) UpdateJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch, req *model.PatchJobRequest) (*model.JobResponse, error) {
+	log.Printf("Updating job with ID: %d\n", id)
+	for _, field := range []string{"url", "interval", "priority", "adaptive"} {
+		if req.Null[field] {
+			return nil, ErrInvalidField(field, "must not be null")
+		}
+	}
+
+	if req.URL != nil {
+		// Check for another job with the same URL
+		existing, err := s.repo.GetByURL(ctx, *req.URL)
+		if err == nil && existing.ID != uint(id) {
+			return nil, ErrJobWithURLExists
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	quota := s.quotas.For(tenantOf(ctx))
+	var intervalChange *model.IntervalChange
+	before, job, err := s.updateJob(ctx, id, ifMatch, func(job *model.Job) error {
+		var err error
+		intervalChange, err = applyJobPatch(job, req, quota)
+		return err
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		// Job with the same URL was created concurrently
+		return nil, ErrJobWithURLExists
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// the job is updated, its history is recorded even if the request is aborted
+	ctx = context.WithoutCancel(ctx)
+
+	if entry := model.NewAuditEntry(actor, model.AuditActionUpdate, before, job); len(entry.Changes) > 0 {
+		s.audit.Record(ctx, entry)
+	}
+
+	if intervalChange != nil {
+		if err := s.intervalRepo.SaveIntervalChange(ctx, intervalChange); err != nil {
+			log.Printf("[ERROR] failed to record interval change of job %d: %v\n", job.ID, err)
+		}
+	}
+
 	return model.ToJobResponse(job), nil
 }
 
-func (s *JobService) getJobByIDOrNotFound(id int) (*model.Job, error) {
-	job, err := s.repo.GetByID(id)
+// applyJobPatch applies req to job and validates the resulting adaptive bounds.
+// Changed intervals must not be shorter than the minimum interval of the quota,
+// unchanged intervals are kept even if the quota was lowered since.
+// It returns the interval change, or nil if the interval is unchanged.
+func applyJobPatch(job *model.Job, req *model.PatchJobRequest, quota model.TenantQuota) (*model.IntervalChange, error) {
+	interval := job.Interval
+	if req.Interval != nil {
+		interval, _ = time.ParseDuration(*req.Interval) // already validated
+		if err := checkMinInterval("interval", interval, quota); err != nil {
+			return nil, err
+		}
+	}
+
+	adaptive := job.Adaptive
+	if req.Adaptive != nil {
+		adaptive = *req.Adaptive
+	}
+
+	minInterval, maxInterval := job.MinInterval, job.MaxInterval
+	if req.MinInterval != nil {
+		minInterval, _ = time.ParseDuration(*req.MinInterval) // already validated
+	} else if req.Null["minInterval"] {
+		minInterval = 0
+	}
+	if req.MaxInterval != nil {
+		maxInterval, _ = time.ParseDuration(*req.MaxInterval) // already validated
+	} else if req.Null["maxInterval"] {
+		maxInterval = 0
+	}
+
+	if adaptive {
+		if minInterval == 0 {
+			return nil, ErrInvalidField("minInterval", "is required for adaptive jobs")
+		}
+		if maxInterval == 0 {
+			return nil, ErrInvalidField("maxInterval", "is required for adaptive jobs")
+		}
+		if minInterval > interval {
+			return nil, ErrInvalidField("minInterval", "must not be greater than interval")
+		}
+		if maxInterval < interval {
+			return nil, ErrInvalidField("maxInterval", "must not be less than interval")
+		}
+		if minInterval != job.MinInterval || !job.Adaptive {
+			if err := checkMinInterval("minInterval", minInterval, quota); err != nil {
+				return nil, err
+			}
+		}
+		job.MinInterval = minInterval
+		job.MaxInterval = maxInterval
+	} else {
+		job.MinInterval = 0
+		job.MaxInterval = 0
+	}
+	if adaptive != job.Adaptive {
+		job.Adaptive = adaptive
+		job.StableRuns = 0
+	}
+
+	if req.URL != nil && *req.URL != job.URL {
+		job.URL = *req.URL
+		// the validators belong to the previous page
+		job.ETag, job.LastModified, job.ContentHash = "", "", ""
+		job.NotModifiedCount = 0
+	}
+
+	if req.Priority != nil {
+		job.Priority = *req.Priority
+	}
+
+	if req.Tags != nil {
+		job.Tags = *req.Tags
+	} else if req.Null["tags"] {
+		job.Tags = model.Tags{}
+	}
+
+	// UpdateInterval schedules the job, a paused, failed or running job keeps its status
+	status := job.Status
+	change := job.UpdateInterval(interval, model.IntervalReasonManual)
+	job.Status = status
+	return change, nil
+}
+
+// updateJob reads the job, applies update and saves it. It returns the job before and after the update.
+// The job must satisfy ifMatch, otherwise ErrPreconditionFailed is returned.
+// Without a precondition the update is retried on the latest job if it was modified concurrently.
+func (s *JobService) updateJob(ctx context.Context, id int, ifMatch *model.IfMatch, update func(job *model.Job) error) (before, after *model.Job, err error) {
+	for attempt := 1; ; attempt++ {
+		job, err := s.getJobByIDOrNotFound(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !ifMatch.Matches(job.Version) {
+			return nil, nil, ErrPreconditionFailed
+		}
+
+		prev := *job
+		if err := update(job); err != nil {
+			return nil, nil, err
+		}
+
+		err = s.repo.Save(ctx, job)
+		if err == nil {
+			return &prev, job, nil
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return nil, nil, err
+		}
+
+		if ifMatch != nil {
+			return nil, nil, ErrPreconditionFailed
+		}
+		if attempt == maxConflictRetries {
+			return nil, nil, ErrJobModified
+		}
+		log.Printf("[INFO] job %d was modified concurrently, retrying\n", id)
+	}
+}
+
+func (s *JobService) getJobByIDOrNotFound(ctx context.Context, id int) (*model.Job, error) {
+	job, err := s.repo.GetByID(ctx, id)
 	if err == nil {
 		return job, nil
 	}
@@ -159,12 +528,120 @@ func (s *JobService) getJobByIDOrNotFound(id int) (*model.Job, error) {
 	return nil, err
 }
 
-func (s *JobService) DeleteJob(id int) error {
+// DeleteJob soft deletes the job, it can be restored until it is purged after the retention period.
+// The job is only deleted if it satisfies ifMatch.
+func (s *JobService) DeleteJob(ctx context.Context, actor model.Actor, id int, ifMatch *model.IfMatch) error {
 	log.Printf("Deleting job with ID: %d\n", id)
-	_, err := s.getJobByIDOrNotFound(id)
+	job, err := s.getJobByIDOrNotFound(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !ifMatch.Matches(job.Version) {
+		return ErrPreconditionFailed
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	deleted := *job
+	now := time.Now()
+	deleted.DeletedAt = &now
+	s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionDelete, job, &deleted))
+	return nil
+}
+
+// RestoreJob restores a soft deleted job. A job deleted while it was in progress is scheduled again,
+// as the result of its last run is discarded. The URL of a deleted job can be used by a new job,
+// the deleted job can only be restored once that job is deleted or uses another URL.
+func (s *JobService) RestoreJob(ctx context.Context, actor model.Actor, id int) (*model.JobResponse, error) {
+	log.Printf("Restoring job with ID: %d\n", id)
+	deleted, err := s.repo.GetDeleted(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		if _, err := s.repo.GetByID(ctx, id); err == nil {
+			return nil, ErrJobNotDeleted
+		}
+		return nil, ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkJobQuota(ctx, s.quotas.For(tenantOf(ctx)), 1); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Restore(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		// restored concurrently
+		return nil, ErrJobNotDeleted
+	}
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrRestoreURLTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.getJobByIDOrNotFound(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == model.JobStatusInProgress {
+		_, job, err = s.updateJob(ctx, id, nil, func(job *model.Job) error {
+			if job.Status == model.JobStatusInProgress {
+				job.ScheduleNextRun()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionRestore, deleted, job))
+	return model.ToJobResponse(job), nil
+}
+
+// checkJobQuota returns an *AppError if n more jobs exceed the job quota of the tenant of ctx.
+// Jobs created concurrently are not accounted for, so they may exceed the quota slightly.
+func (s *JobService) checkJobQuota(ctx context.Context, quota model.TenantQuota, n int) error {
+	remaining, err := s.remainingJobs(ctx, quota)
 	if err != nil {
 		return err
 	}
+	if n > remaining {
+		return ErrJobQuotaExceeded(quota.MaxJobs)
+	}
+	return nil
+}
+
+// remainingJobs returns the number of jobs the tenant of ctx can create within its quota.
+func (s *JobService) remainingJobs(ctx context.Context, quota model.TenantQuota) (int, error) {
+	if quota.MaxJobs <= 0 {
+		return math.MaxInt, nil
+	}
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return max(quota.MaxJobs-int(count), 0), nil
+}
 
-	return s.repo.Delete(id)
+// checkMinInterval returns an *AppError for the field if interval is shorter than the minimum interval of the quota.
+func checkMinInterval(field string, interval time.Duration, quota model.TenantQuota) error {
+	if interval < quota.MinInterval {
+		return ErrInvalidField(field, fmt.Sprintf("must be at least %s, the minimum interval of the tenant", quota.MinInterval))
+	}
+	return nil
+}
+
+// tenantOf returns the tenant ctx is scoped to, the default tenant if ctx is not scoped to a tenant.
+func tenantOf(ctx context.Context) string {
+	if tenant, ok := repository.TenantFromContext(ctx); ok {
+		return tenant
+	}
+	return model.DefaultTenant
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+// PriceRepository defines methods to store and query price observations.
+type PriceRepository interface {
+	// SaveObservation inserts a new observation and assigns its ID.
+	SaveObservation(ctx context.Context, observation *model.PriceObservation) error
+
+	// LatestObservation returns the most recent observation of a job.
+	// Returns repository.ErrNotFound if the job has no observations.
+	LatestObservation(ctx context.Context, jobID uint) (*model.PriceObservation, error)
+
+	// ListObservations returns the raw observations of a job within the filter's time range.
+	ListObservations(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.PriceObservation, *pagination.Pagination, error)
+
+	// ListDaily returns the observations of a job downsampled to one entry per day.
+	ListDaily(ctx context.Context, jobID uint, filter *model.ListPricesFilter) ([]*model.DailyPrice, *pagination.Pagination, error)
+}
+
+type PriceService struct {
+	jobRepo   JobRepository
+	priceRepo PriceRepository
+}
+
+// NewPriceService instantiates a PriceService
+func NewPriceService(jobRepo JobRepository, priceRepo PriceRepository) *PriceService {
+	return &PriceService{
+		jobRepo:   jobRepo,
+		priceRepo: priceRepo,
+	}
+}
+
+// ListPrices returns the raw price history of a job.
+func (s *PriceService) ListPrices(ctx context.Context, jobID int, filter *model.ListPricesFilter) (*model.PaginatedPricesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List prices of job %d %+v\n", jobID, filter)
+	if err := s.validatePriceQuery(ctx, jobID, filter); err != nil {
+		return nil, err
+	}
+
+	observations, pagination, err := s.priceRepo.ListObservations(ctx, uint(jobID), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.PriceObservationResponse, 0, len(observations))
+	for _, observation := range observations {
+		items = append(items, model.ToPriceObservationResponse(observation))
+	}
+
+	return &model.PaginatedPricesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// ListDailyPrices returns the price history of a job downsampled to min/max/last per day.
+func (s *PriceService) ListDailyPrices(ctx context.Context, jobID int, filter *model.ListPricesFilter) (*model.PaginatedDailyPricesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("List daily prices of job %d %+v\n", jobID, filter)
+	if err := s.validatePriceQuery(ctx, jobID, filter); err != nil {
+		return nil, err
+	}
+
+	days, pagination, err := s.priceRepo.ListDaily(ctx, uint(jobID), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.DailyPriceResponse, 0, len(days))
+	for _, day := range days {
+		items = append(items, model.ToDailyPriceResponse(day))
+	}
+
+	return &model.PaginatedDailyPricesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+// ExportPrices passes every raw observation of a job within the time range of filter to write,
+// ignoring the pagination of filter. The observations are read page by page,
+// so only a single page is held in memory. The export stops at the first error returned by write.
+func (s *PriceService) ExportPrices(ctx context.Context, jobID int, filter *model.ListPricesFilter, write func(*model.PriceObservationResponse) error) error {
+	ctx = repository.WithReplicaReads(ctx)
+	log.Printf("Export prices of job %d %+v\n", jobID, filter)
+	if err := s.validatePriceQuery(ctx, jobID, filter); err != nil {
+		return err
+	}
+
+	page := *filter
+	page.PageSize = pagination.MAX_PAGE_SIZE
+	for page.Page = 1; ; page.Page++ {
+		observations, p, err := s.priceRepo.ListObservations(ctx, uint(jobID), &page)
+		if err != nil {
+			return err
+		}
+		for _, observation := range observations {
+			if err := write(model.ToPriceObservationResponse(observation)); err != nil {
+				return err
+			}
+		}
+		if page.Page >= p.TotalPages() {
+			return nil
+		}
+	}
+}
+
+func (s *PriceService) validatePriceQuery(ctx context.Context, jobID int, filter *model.ListPricesFilter) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return ErrInvalidField("from", "must be before 'to'")
+	}
+
+	if _, err := s.jobRepo.GetByID(ctx, jobID); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound(jobID)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+)
+
+// AlertEvaluator evaluates alert rules when a new price observation arrives.
+type AlertEvaluator interface {
+	// Evaluate checks the rules of the job against the transition from prev to curr.
+	// prev is nil for the first observation of a job.
+	Evaluate(ctx context.Context, job *model.Job, prev, curr *model.PriceObservation) ([]*model.AlertEvent, error)
+}
+
+// ResultService processes the results workers report for dispatched jobs.
+type ResultService struct {
+	jobRepo          JobRepository
+	priceRepo        PriceRepository
+	intervalRepo     IntervalChangeRepository
+	runRepo          RunRepository
+	alerts           AlertEvaluator
+	audit            Auditor
+	events           Publisher
+	maxRetryAttempts int
+}
+
+// NewResultService instantiates a ResultService.
+// Failed jobs are retried up to maxRetryAttempts times before they are marked as failed.
+// Status changes caused by results are recorded by audit, completed and failed runs
+// as well as changed prices are published to events.
+func NewResultService(jobRepo JobRepository, priceRepo PriceRepository, intervalRepo IntervalChangeRepository, runRepo RunRepository, alerts AlertEvaluator, audit Auditor, events Publisher, maxRetryAttempts int) *ResultService {
+	return &ResultService{
+		jobRepo:          jobRepo,
+		priceRepo:        priceRepo,
+		intervalRepo:     intervalRepo,
+		runRepo:          runRepo,
+		alerts:           alerts,
+		audit:            audit,
+		events:           events,
+		maxRetryAttempts: maxRetryAttempts,
+	}
+}
+
+// ReportResult records the outcome of a job run.
+// On success the observed price is stored and the job is scheduled for its next run.
+// If the page was not modified, no price is stored but the run counts as successful.
+// On failure the job is retried until the retry limit is exceeded.
+// Adaptive jobs adjust their interval depending on whether the price changed.
+// Runs dispatched on request only store the price and do not affect the status or schedule of the job.
+// If the job is modified concurrently (e.g. paused), the outcome is applied again to the latest job.
+// The result is also recorded in the run history of the job, and a changed status in the audit log.
+func (s *ResultService) ReportResult(ctx context.Context, actor model.Actor, jobID int, req *model.ReportResultRequest) (*model.JobResponse, error) {
+	log.Printf("Reporting result for job %d: outcome=%s\n", jobID, req.Outcome)
+	job, err := s.getJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.findRun(ctx, job, req.RunID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the observation is stored once, only the job update is retried on conflicts
+	var observation *model.PriceObservation
+	priceChanged := false
+	if req.Outcome == model.RunOutcomeSuccess {
+		observation = toPriceObservation(job, req)
+		priceChanged, err = s.recordObservation(ctx, job, observation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var before model.Job
+	var intervalChange *model.IntervalChange
+	for attempt := 1; ; attempt++ {
+		before = *job
+		if run != nil && run.IsManual() {
+			applyManualOutcome(job, run, req)
+		} else {
+			intervalChange = s.applyOutcome(job, req, priceChanged)
+		}
+
+		err := s.jobRepo.Save(ctx, job)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrConflict) {
+			return nil, err
+		}
+		if attempt == maxConflictRetries {
+			return nil, ErrJobModified
+		}
+
+		log.Printf("[INFO] job %d was modified concurrently, applying result again\n", jobID)
+		if job, err = s.getJob(ctx, jobID); err != nil {
+			return nil, err
+		}
+	}
+
+	// the job is updated, its history is recorded even if the request is aborted
+	ctx = context.WithoutCancel(ctx)
+
+	if before.Status != job.Status {
+		s.audit.Record(ctx, model.NewAuditEntry(actor, model.AuditActionStatusChange, &before, job))
+	}
+
+	if run != nil {
+		run.Finish(req, time.Now())
+		if err := s.runRepo.SaveRun(ctx, run); err != nil {
+			log.Printf("[ERROR] failed to record run %d of job %d: %v\n", run.ID, job.ID, err)
+		}
+	}
+
+	events := []*model.Event{resultEvent(job, run, req)}
+	if priceChanged {
+		events = append(events, model.NewPriceEvent(job, observation))
+	}
+	s.events.Publish(events...)
+
+	if intervalChange != nil {
+		log.Printf("[INFO] interval of job %d changed from %s to %s (%s)\n",
+			job.ID, intervalChange.OldInterval, intervalChange.NewInterval, intervalChange.Reason)
+		if err := s.intervalRepo.SaveIntervalChange(ctx, intervalChange); err != nil {
+			log.Printf("[ERROR] failed to record interval change of job %d: %v\n", job.ID, err)
+		}
+	}
+
+	return model.ToJobResponse(job), nil
+}
+
+// resultEvent returns the event of the reported outcome, run is nil if the result has no recorded run.
+func resultEvent(job *model.Job, run *model.JobRun, req *model.ReportResultRequest) *model.Event {
+	eventType := model.EventJobCompleted
+	if req.Outcome == model.RunOutcomeFailure {
+		eventType = model.EventJobFailed
+	}
+	if run != nil {
+		return model.NewRunEvent(eventType, job, run)
+	}
+	event := model.NewJobEvent(eventType, job)
+	event.Error = req.Error
+	return event
+}
+
+// applyOutcome updates the state of the job according to the reported outcome.
+// It returns the interval change of adaptive jobs, or nil if the interval was kept.
+func (s *ResultService) applyOutcome(job *model.Job, req *model.ReportResultRequest, priceChanged bool) *model.IntervalChange {
+	switch req.Outcome {
+	case model.RunOutcomeSuccess:
+		job.UpdateValidators(req.Validators())
+		return completeRun(job, priceChanged)
+	case model.RunOutcomeNotModified:
+		job.UpdateValidators(req.Validators())
+		job.NotModifiedCount++
+		return completeRun(job, false)
+	case model.RunOutcomeFailure:
+		log.Printf("[WARN] job %d failed: %s\n", job.ID, req.Error)
+		if job.Status == model.JobStatusInProgress {
+			job.RetryAttempts++
+			if job.ShouldRetry(s.maxRetryAttempts) {
+				job.ScheduleNextRun()
+			} else {
+				job.Status = model.JobStatusFailed
+			}
+		}
+	}
+	return nil
+}
+
+// applyManualOutcome updates the validators of the job after a run dispatched on request
+// and restores the status the job had before the run. Retries and schedule are left untouched.
+func applyManualOutcome(job *model.Job, run *model.JobRun, req *model.ReportResultRequest) {
+	if req.Outcome == model.RunOutcomeSuccess || req.Outcome == model.RunOutcomeNotModified {
+		job.UpdateValidators(req.Validators())
+	}
+	job.RestoreStatus(run)
+}
+
+// findRun returns the run the result belongs to: the run with runID if given,
+// otherwise the latest unfinished run of the job. Workers report the runID they
+// were dispatched with, the fallback only serves workers from before runs were
+// recorded and may attribute the result to the wrong run if runs overlap.
+// It returns nil if the job has no unfinished run.
+func (s *ResultService) findRun(ctx context.Context, job *model.Job, runID *uint) (*model.JobRun, error) {
+	if runID == nil {
+		log.Printf("[WARN] result of job %d has no runId, assigning it to the latest unfinished run; update the worker to report the runId\n", job.ID)
+		run, err := s.runRepo.LatestOpenRun(ctx, job.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Printf("[WARN] job %d has no unfinished run to record the result\n", job.ID)
+			return nil, nil
+		}
+		return run, err
+	}
+
+	run, err := s.runRepo.GetRun(ctx, *runID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && run.JobID != job.ID) {
+		return nil, ErrRunNotFound(*runID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if run.IsFinished() {
+		return nil, ErrRunFinished
+	}
+	return run, nil
+}
+
+func (s *ResultService) getJob(ctx context.Context, id int) (*model.Job, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound(id)
+	}
+	return job, err
+}
+
+// completeRun schedules the next run of a job that finished successfully.
+// Jobs that are no longer in progress (e.g. paused in the meantime) are left untouched.
+func completeRun(job *model.Job, priceChanged bool) *model.IntervalChange {
+	if job.Status != model.JobStatusInProgress {
+		return nil
+	}
+	job.RetryAttempts = 0
+	job.ScheduleNextRun()
+	return job.AdaptInterval(priceChanged)
+}
+
+// recordObservation stores the observation and evaluates the alert rules of the job against it.
+// It reports whether the price or availability changed compared to the previous observation.
+// Failing alert evaluation is logged but does not fail the result report, as the observation is already stored.
+func (s *ResultService) recordObservation(ctx context.Context, job *model.Job, observation *model.PriceObservation) (bool, error) {
+	prev, err := s.priceRepo.LatestObservation(ctx, job.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return false, err
+	}
+
+	if err := s.priceRepo.SaveObservation(ctx, observation); err != nil {
+		return false, err
+	}
+
+	if _, err := s.alerts.Evaluate(ctx, job, prev, observation); err != nil {
+		log.Printf("[ERROR] failed to evaluate alerts for job %d: %v\n", job.ID, err)
+	}
+	return observation.ChangedFrom(prev), nil
+}
+
+func toPriceObservation(job *model.Job, req *model.ReportResultRequest) *model.PriceObservation {
+	observedAt := time.Now()
+	if req.ObservedAt != nil {
+		observedAt = *req.ObservedAt
+	}
+
+	availability := model.AvailabilityUnknown
+	if req.Availability != nil {
+		availability = *req.Availability
+	}
+
+	return &model.PriceObservation{
+		JobID:        job.ID,
+		ObservedAt:   observedAt,
+		Amount:       *req.Amount,
+		Currency:     req.Currency,
+		Availability: availability,
+		SnippetHash:  req.SnippetHash,
+	}
+}
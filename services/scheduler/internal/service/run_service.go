@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+)
+
+// Dispatcher submits jobs to the worker queue.
+type Dispatcher interface {
+	// DispatchJobs dispatches all jobs as a batch to the worker queue.
+	DispatchJobs(ctx context.Context, jobs []model.JobDispatched) error
+}
+
+// RunRecorder stores the runs of jobs dispatched on request.
+type RunRecorder interface {
+	// SaveRun updates a run.
+	SaveRun(ctx context.Context, run *model.JobRun) error
+
+	// StartRuns saves the dispatched jobs and inserts their runs in a single transaction,
+	// runs[i] being the run of jobs[i], and assigns the IDs of the runs.
+	// Jobs modified concurrently are not saved and reported by a *repository.ConflictError.
+	StartRuns(ctx context.Context, jobs []*model.Job, runs []*model.JobRun) error
+}
+
+// defaultRunLimit is the number of jobs run by RunJobs if no limit is given.
+const defaultRunLimit = 100
+
+type RunService struct {
+	jobRepo    JobRepository
+	runRepo    RunRecorder
+	dispatcher Dispatcher
+	audit      Auditor
+	events     Publisher
+}
+
+// NewRunService instantiates a RunService.
+// Jobs run on request are submitted to the worker queue by dispatcher and published to events,
+// their status changes are recorded by audit.
+func NewRunService(jobRepo JobRepository, runRepo RunRecorder, dispatcher Dispatcher, audit Auditor, events Publisher) *RunService {
+	return &RunService{
+		jobRepo:    jobRepo,
+		runRepo:    runRepo,
+		dispatcher: dispatcher,
+		audit:      audit,
+		events:     events,
+	}
+}
+
+// RunJob dispatches the job immediately, regardless of its next run.
+// The job is in progress until the result is reported, then its previous status is restored.
+// Its schedule is kept, the result of the run only stores the observed price.
+// Jobs in progress are refused, as their run did not finish yet.
+func (s *RunService) RunJob(ctx context.Context, actor model.Actor, id int) (*model.JobRunResponse, error) {
+	log.Printf("Running job with ID: %d\n", id)
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNotFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == model.JobStatusInProgress {
+		return nil, ErrJobInProgress
+	}
+
+	runs, skipped, err := s.dispatch(ctx, actor, []*model.Job{job})
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		return nil, ErrJobModified
+	}
+	return model.ToJobRunResponse(runs[0]), nil
+}
+
+// RunJobs dispatches the jobs matching the request immediately, like RunJob.
+// Jobs in progress or modified concurrently are skipped and reported in the response.
+func (s *RunService) RunJobs(ctx context.Context, actor model.Actor, req *model.RunJobsRequest) (*model.RunJobsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultRunLimit
+	}
+	log.Printf("Running up to %d jobs matching %+v\n", limit, req)
+
+	jobs, _, err := s.jobRepo.List(ctx, &model.ListJobsFilter{
+		URL:      req.URL,
+		Status:   req.Status,
+		Tag:      req.Tag,
+		PageSize: limit,
+		Page:     1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.RunJobsResponse{
+		Runs:    []*model.JobRunResponse{},
+		Skipped: []*model.SkippedJob{},
+	}
+
+	var due []*model.Job
+	for _, job := range jobs {
+		if job.Status == model.JobStatusInProgress {
+			resp.Skipped = append(resp.Skipped, &model.SkippedJob{JobID: job.ID, Reason: ErrJobInProgress.Message})
+			continue
+		}
+		due = append(due, job)
+	}
+	if len(due) == 0 {
+		return resp, nil
+	}
+
+	runs, skipped, err := s.dispatch(ctx, actor, due)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, model.ToJobRunResponse(run))
+	}
+	for _, id := range skipped {
+		resp.Skipped = append(resp.Skipped, &model.SkippedJob{JobID: id, Reason: ErrJobModified.Message})
+	}
+	return resp, nil
+}
+
+// dispatch marks the jobs in progress, records a manual run for every job in the same transaction
+// and submits the jobs to the worker queue. It returns the runs of the dispatched jobs and the IDs
+// of the jobs skipped because they were modified concurrently. If the submission fails, the runs
+// are finished as failures and the jobs get their previous status back, no worker will report them.
+func (s *RunService) dispatch(ctx context.Context, actor model.Actor, jobs []*model.Job) ([]*model.JobRun, []uint, error) {
+	dispatchedAt := time.Now()
+
+	before := make(map[uint]model.Job, len(jobs))
+	runs := make([]*model.JobRun, 0, len(jobs))
+	for _, job := range jobs {
+		before[job.ID] = *job
+		runs = append(runs, model.NewManualJobRun(job, dispatchedAt))
+		job.Status = model.JobStatusInProgress
+		job.DispatchedAt = &dispatchedAt
+	}
+
+	// mark the jobs in progress and record the runs, their IDs are sent to the workers to report results
+	var skipped []uint
+	if err := s.runRepo.StartRuns(ctx, jobs, runs); err != nil {
+		var conflict *repository.ConflictError
+		if !errors.As(err, &conflict) {
+			return nil, nil, fmt.Errorf("failed to record job runs: %w", err)
+		}
+		log.Printf("[INFO] skipping %d jobs modified concurrently: %v\n", len(conflict.IDs), conflict.IDs)
+		skipped = conflict.IDs
+	}
+
+	isSkipped := make(map[uint]bool, len(skipped))
+	for _, id := range skipped {
+		isSkipped[id] = true
+	}
+	var started []*model.Job
+	var startedRuns []*model.JobRun
+	var entries []*model.AuditEntry
+	for i, job := range jobs {
+		if isSkipped[job.ID] {
+			continue
+		}
+		prev := before[job.ID]
+		started = append(started, job)
+		startedRuns = append(startedRuns, runs[i])
+		entries = append(entries, model.NewAuditEntry(actor, model.AuditActionStatusChange, &prev, job))
+	}
+	if len(started) == 0 {
+		return nil, skipped, nil
+	}
+
+	// the jobs are in progress, their history is recorded even if the request is aborted
+	saveCtx := context.WithoutCancel(ctx)
+	s.audit.Record(saveCtx, entries...)
+
+	jobsDispatched := make([]model.JobDispatched, 0, len(started))
+	for i, job := range started {
+		jobsDispatched = append(jobsDispatched, model.JobDispatched{
+			ID:           job.ID,
+			RunID:        startedRuns[i].ID,
+			URL:          job.URL,
+			DispatchedAt: dispatchedAt,
+			Validators:   job.Validators(),
+		})
+	}
+
+	err := s.dispatcher.DispatchJobs(ctx, jobsDispatched)
+	if err == nil {
+		events := make([]*model.Event, 0, len(started))
+		for i, job := range started {
+			events = append(events, model.NewRunEvent(model.EventJobDispatched, job, startedRuns[i]))
+		}
+		s.events.Publish(events...)
+		return startedRuns, skipped, nil
+	}
+
+	failure := &model.ReportResultRequest{Outcome: model.RunOutcomeFailure, Error: "dispatch failed: " + err.Error()}
+	for i, run := range startedRuns {
+		run.Finish(failure, time.Now())
+		if err := s.runRepo.SaveRun(saveCtx, run); err != nil {
+			log.Printf("[ERROR] failed to record run %d of job %d: %v\n", run.ID, run.JobID, err)
+		}
+		started[i].RestoreStatus(run)
+	}
+	if err := s.jobRepo.SaveAll(saveCtx, started); err != nil {
+		log.Printf("[ERROR] failed to restore the status of jobs not dispatched: %v\n", err)
+	}
+	log.Printf("[ERROR] failed to dispatch %d jobs: %v\n", len(started), err)
+	return nil, nil, ErrDispatchFailed
+}
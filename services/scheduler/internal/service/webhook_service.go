@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/model"
+	"github.com/lorenzhoerb/cogniprice/services/scheduler/internal/repository"
+	"github.com/lorenzhoerb/cogniprice/shared/pagination"
+)
+
+// WebhookRepository defines methods to manage webhook subscriptions and their delivery log.
+type WebhookRepository interface {
+	// SaveSubscription inserts or updates a subscription.
+	SaveSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+
+	// GetSubscription retrieves a subscription by its ID.
+	GetSubscription(ctx context.Context, id int) (*model.WebhookSubscription, error)
+
+	// ListSubscriptions lists all subscriptions matching the filter.
+	ListSubscriptions(ctx context.Context, filter *model.ListWebhooksFilter) ([]*model.WebhookSubscription, *pagination.Pagination, error)
+
+	// DeleteSubscription removes a subscription and its delivery log.
+	DeleteSubscription(ctx context.Context, id int) error
+
+	// ListDeliveries lists the deliveries to a subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID uint, filter *model.ListWebhookDeliveriesFilter) ([]*model.WebhookDelivery, *pagination.Pagination, error)
+}
+
+type WebhookService struct {
+	repo WebhookRepository
+}
+
+// NewWebhookService instantiates a WebhookService
+func NewWebhookService(repo WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+	}
+}
+
+func (s *WebhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookResponse, error) {
+	log.Printf("Creating webhook for %s\n", req.URL)
+	subscription := &model.WebhookSubscription{
+		TenantID:   tenantOf(ctx),
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Enabled:    true,
+	}
+
+	if err := s.repo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return model.ToWebhookResponse(subscription), nil
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id int) (*model.WebhookResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	subscription, err := s.getSubscriptionOrNotFound(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return model.ToWebhookResponse(subscription), nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, filter *model.ListWebhooksFilter) (*model.PaginatedWebhooksResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	subscriptions, pagination, err := s.repo.ListSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		items = append(items, model.ToWebhookResponse(subscription))
+	}
+
+	return &model.PaginatedWebhooksResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	log.Printf("Deleting webhook with ID: %d\n", id)
+	if _, err := s.getSubscriptionOrNotFound(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, id)
+}
+
+// EnableWebhook re-enables a webhook that was disabled after repeated failed deliveries.
+func (s *WebhookService) EnableWebhook(ctx context.Context, id int) (*model.WebhookResponse, error) {
+	log.Printf("Enabling webhook with ID: %d\n", id)
+	subscription, err := s.getSubscriptionOrNotFound(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Enabled = true
+	subscription.ConsecutiveFailures = 0
+	subscription.DisabledAt = nil
+	if err := s.repo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return model.ToWebhookResponse(subscription), nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, id int, filter *model.ListWebhookDeliveriesFilter) (*model.PaginatedWebhookDeliveriesResponse, error) {
+	ctx = repository.WithReplicaReads(ctx)
+	if _, err := s.getSubscriptionOrNotFound(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, pagination, err := s.repo.ListDeliveries(ctx, uint(id), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		items = append(items, model.ToWebhookDeliveryResponse(delivery))
+	}
+
+	return &model.PaginatedWebhookDeliveriesResponse{
+		Items:      items,
+		TotalCount: pagination.Total,
+		TotalPages: pagination.TotalPages(),
+		Page:       pagination.CurrentPage(),
+		PageSize:   pagination.PageSize,
+	}, nil
+}
+
+func (s *WebhookService) getSubscriptionOrNotFound(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	subscription, err := s.repo.GetSubscription(ctx, id)
+	if err == nil {
+		return subscription, nil
+	}
+
+	if err == repository.ErrNotFound {
+		return nil, ErrWebhookNotFound(id)
+	}
+
+	return nil, err
+}
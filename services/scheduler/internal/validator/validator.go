@@ -50,11 +50,83 @@ var jobSortCol validator.Func = func(fl validator.FieldLevel) bool {
 	return col == "created_at" || col == "url" || col == "next_run_at" || col == "status"
 }
 
+var runOutcome validator.Func = func(fl validator.FieldLevel) bool {
+	outcome, ok := fl.Field().Interface().(model.RunOutcome)
+	if !ok {
+		return false
+	}
+	return outcome.IsValid()
+}
+
+var availability validator.Func = func(fl validator.FieldLevel) bool {
+	a, ok := fl.Field().Interface().(model.Availability)
+	if !ok {
+		return false
+	}
+	return a.IsValid()
+}
+
+var alertRuleType validator.Func = func(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(model.AlertRuleType)
+	if !ok {
+		return false
+	}
+	return t.IsValid()
+}
+
+var auditAction validator.Func = func(fl validator.FieldLevel) bool {
+	a, ok := fl.Field().Interface().(model.AuditAction)
+	if !ok {
+		return false
+	}
+	return a.IsValid()
+}
+
+var bulkAction validator.Func = func(fl validator.FieldLevel) bool {
+	a, ok := fl.Field().Interface().(model.BulkAction)
+	if !ok {
+		return false
+	}
+	return a.IsValid()
+}
+
+var eventType validator.Func = func(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(model.EventType)
+	if !ok {
+		return false
+	}
+	return t.IsValid()
+}
+
+var role validator.Func = func(fl validator.FieldLevel) bool {
+	r, ok := fl.Field().Interface().(model.Role)
+	if !ok {
+		return false
+	}
+	return r.IsValid()
+}
+
+var tenant validator.Func = func(fl validator.FieldLevel) bool {
+	id, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return model.IsValidTenant(id)
+}
+
 func RegisterValidators() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("interval", interval)
 		v.RegisterValidation("jobstatus", jobStatus)
 		v.RegisterValidation("sortorder", sortOrder)
 		v.RegisterValidation("jobsortcol", jobSortCol)
+		v.RegisterValidation("runoutcome", runOutcome)
+		v.RegisterValidation("availability", availability)
+		v.RegisterValidation("alertruletype", alertRuleType)
+		v.RegisterValidation("auditaction", auditAction)
+		v.RegisterValidation("bulkaction", bulkAction)
+		v.RegisterValidation("eventtype", eventType)
+		v.RegisterValidation("role", role)
+		v.RegisterValidation("tenant", tenant)
 	}
 }
@@ -22,7 +22,7 @@ func NewPagination(page, pageSize int) *Pagination {
 
 	if pageSize <= 0 {
 		pageSize = DEFAULT_PAGE_SIZE
-	} else if page >= MAX_PAGE_SIZE {
+	} else if pageSize >= MAX_PAGE_SIZE {
 		pageSize = MAX_PAGE_SIZE
 	}
 
@@ -39,7 +39,7 @@ func (p *Pagination) Limit() int {
 
 // Offset returns the SQL offset
 func (p *Pagination) Offset() int {
-	return (1 - p.Page) * p.PageSize
+	return (p.Page - 1) * p.PageSize
 }
 
 // TotalPages calculates the number of pages
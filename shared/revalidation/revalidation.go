@@ -0,0 +1,140 @@
+package revalidation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// MaxBodySize is the number of bytes of a page read by Fetch, the rest is ignored.
+const MaxBodySize = 10 << 20
+
+var (
+	commentPattern    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	scriptPattern     = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script>`)
+	structuredPattern = regexp.MustCompile(`(?i)\btype\s*=\s*["']?application/ld\+json`)
+	noncePattern      = regexp.MustCompile(`(?i)\snonce\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	csrfPattern       = regexp.MustCompile(`(?i)<(meta|input)\b[^>]*\b(csrf|xsrf)[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	betweenTags       = regexp.MustCompile(`>\s+<`)
+)
+
+// Validators are the values used to detect whether a page changed since the last crawl.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	ContentHash  string `json:"contentHash,omitempty"`
+}
+
+// Apply sets the conditional request headers (If-None-Match / If-Modified-Since)
+// so the server can answer with 304 Not Modified.
+func (v Validators) Apply(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// FromResponse returns the validators of a response and its body.
+func FromResponse(resp *http.Response, body []byte) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentHash:  ContentHash(body),
+	}
+}
+
+// NotModified reports whether the response indicates that the page did not change.
+// This is either the case if the server answered 304 Not Modified or if the
+// normalized content hash of the body equals the previous hash.
+func NotModified(prev Validators, resp *http.Response, body []byte) bool {
+	if resp.StatusCode == http.StatusNotModified {
+		return true
+	}
+	return prev.ContentHash != "" && prev.ContentHash == ContentHash(body)
+}
+
+// Page is a page fetched by Fetch.
+type Page struct {
+	StatusCode int
+	// Body is empty if the server answered 304 Not Modified.
+	Body []byte
+	// Validators are sent with the next fetch of the page.
+	Validators Validators
+	// NotModified reports that the page did not change since the previous fetch,
+	// so the price does not need to be extracted again.
+	NotModified bool
+}
+
+// Fetch requests the page at url, conditionally if validators of a previous fetch are given.
+// If the server answers with a status other than 2xx or 304, the page is returned
+// together with an error, so the status can still be reported.
+func Fetch(ctx context.Context, client *http.Client, url string, prev Validators) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	prev.Apply(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	page := &Page{StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusNotModified {
+		// a 304 response has no body, the content is unchanged
+		page.Validators = prev
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			page.Validators.ETag = etag
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			page.Validators.LastModified = lastModified
+		}
+		page.NotModified = true
+		return page, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return page, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	page.Body = body
+	page.Validators = FromResponse(resp, body)
+	page.NotModified = NotModified(prev, resp, body)
+	return page, nil
+}
+
+// ContentHash returns the hex encoded SHA-256 of the normalized body.
+// Normalization removes what often changes on every request without the page
+// changing: HTML comments, scripts, nonces and CSRF tokens. Whitespace is collapsed,
+// and removed between tags.
+// Structured data scripts (application/ld+json) are kept, as they usually carry the price.
+func ContentHash(body []byte) string {
+	normalized := commentPattern.ReplaceAll(body, nil)
+	normalized = scriptPattern.ReplaceAllFunc(normalized, func(script []byte) []byte {
+		openingTag := script[:bytes.IndexByte(script, '>')+1]
+		if structuredPattern.Match(openingTag) {
+			return script
+		}
+		return nil
+	})
+	normalized = noncePattern.ReplaceAll(normalized, nil)
+	normalized = csrfPattern.ReplaceAll(normalized, nil)
+	normalized = whitespacePattern.ReplaceAll(normalized, []byte(" "))
+	normalized = betweenTags.ReplaceAll(normalized, []byte("><"))
+
+	sum := sha256.Sum256(bytes.TrimSpace(normalized))
+	return hex.EncodeToString(sum[:])
+}
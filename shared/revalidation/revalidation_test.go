@@ -0,0 +1,146 @@
+package revalidation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentHashIgnoresVolatileContent(t *testing.T) {
+	page := `<html><head><meta name="csrf-token" content="a1b2"><script nonce="x1">var t = 1;</script></head><body><!-- rendered in 12ms --><p class="price">19.99 EUR</p></body></html>`
+
+	equal := map[string]string{
+		"whitespace": "  <html><head><meta name=\"csrf-token\" content=\"a1b2\"><script nonce=\"x1\">var t = 1;</script></head>\n\n" +
+			"<body>\t<!-- rendered in 12ms -->   <p class=\"price\">19.99   EUR</p>\n</body></html>\n",
+		"comment":    `<html><head><meta name="csrf-token" content="a1b2"><script nonce="x1">var t = 1;</script></head><body><!-- rendered in 48ms --><p class="price">19.99 EUR</p></body></html>`,
+		"script":     `<html><head><meta name="csrf-token" content="a1b2"><script nonce="x1">var t = 2;</script></head><body><!-- rendered in 12ms --><p class="price">19.99 EUR</p></body></html>`,
+		"nonce":      `<html><head><meta name="csrf-token" content="a1b2"><script nonce="y2">var t = 1;</script></head><body><!-- rendered in 12ms --><p class="price">19.99 EUR</p></body></html>`,
+		"csrf token": `<html><head><meta name="csrf-token" content="c3d4"><script nonce="x1">var t = 1;</script></head><body><!-- rendered in 12ms --><p class="price">19.99 EUR</p></body></html>`,
+	}
+	for name, other := range equal {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, ContentHash([]byte(page)), ContentHash([]byte(other)))
+		})
+	}
+
+	changed := map[string]string{
+		"price": `<html><head><meta name="csrf-token" content="a1b2"><script nonce="x1">var t = 1;</script></head><body><!-- rendered in 12ms --><p class="price">17.99 EUR</p></body></html>`,
+		"text":  `<html><head><meta name="csrf-token" content="a1b2"><script nonce="x1">var t = 1;</script></head><body><!-- rendered in 12ms --><p class="price">19.99 EUR</p><p>sold out</p></body></html>`,
+	}
+	for name, other := range changed {
+		t.Run(name, func(t *testing.T) {
+			assert.NotEqual(t, ContentHash([]byte(page)), ContentHash([]byte(other)))
+		})
+	}
+}
+
+func TestContentHashKeepsStructuredData(t *testing.T) {
+	page := `<script type="application/ld+json">{"@type":"Offer","price":"19.99"}</script><p>Product</p>`
+	changed := `<script type="application/ld+json">{"@type":"Offer","price":"17.99"}</script><p>Product</p>`
+
+	assert.NotEqual(t, ContentHash([]byte(page)), ContentHash([]byte(changed)))
+}
+
+func TestValidatorsApply(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://shop.test", nil)
+
+	Validators{ETag: `"v1"`, LastModified: "Mon, 02 Mar 2026 10:00:00 GMT", ContentHash: "abc"}.Apply(req)
+
+	assert.Equal(t, `"v1"`, req.Header.Get("If-None-Match"))
+	assert.Equal(t, "Mon, 02 Mar 2026 10:00:00 GMT", req.Header.Get("If-Modified-Since"))
+
+	req = httptest.NewRequest(http.MethodGet, "https://shop.test", nil)
+	Validators{}.Apply(req)
+	assert.Empty(t, req.Header.Get("If-None-Match"))
+	assert.Empty(t, req.Header.Get("If-Modified-Since"))
+}
+
+// shop serves a page with an ETag and Last-Modified and answers conditional requests with 304.
+func shop(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Mar 2026 10:00:00 GMT")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := shop(t, "<p>19.99 EUR</p>")
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL, Validators{})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, page.StatusCode)
+	assert.False(t, page.NotModified)
+	assert.Equal(t, "<p>19.99 EUR</p>", string(page.Body))
+	assert.Equal(t, Validators{
+		ETag:         `"v1"`,
+		LastModified: "Mon, 02 Mar 2026 10:00:00 GMT",
+		ContentHash:  ContentHash([]byte("<p>19.99 EUR</p>")),
+	}, page.Validators)
+}
+
+func TestFetchNotModifiedResponse(t *testing.T) {
+	server := shop(t, "<p>19.99 EUR</p>")
+	prev := Validators{ETag: `"v1"`, ContentHash: "previous-hash"}
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL, prev)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotModified, page.StatusCode)
+	assert.True(t, page.NotModified)
+	assert.Empty(t, page.Body)
+	assert.Equal(t, Validators{
+		ETag:         `"v1"`,
+		LastModified: "Mon, 02 Mar 2026 10:00:00 GMT",
+		ContentHash:  "previous-hash",
+	}, page.Validators, "the content hash of the previous fetch is kept")
+}
+
+func TestFetchUnchangedContent(t *testing.T) {
+	server := shop(t, "<p>19.99 EUR</p><!-- request 2 -->")
+	prev := Validators{ETag: `"v0"`, ContentHash: ContentHash([]byte("<p>19.99 EUR</p><!-- request 1 -->"))}
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL, prev)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, page.StatusCode)
+	assert.True(t, page.NotModified, "identical content hash")
+	assert.Equal(t, `"v1"`, page.Validators.ETag)
+}
+
+func TestFetchChangedContent(t *testing.T) {
+	server := shop(t, "<p>17.99 EUR</p>")
+	prev := Validators{ETag: `"v0"`, ContentHash: ContentHash([]byte("<p>19.99 EUR</p>"))}
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL, prev)
+	require.NoError(t, err)
+
+	assert.False(t, page.NotModified)
+	assert.Equal(t, ContentHash([]byte("<p>17.99 EUR</p>")), page.Validators.ContentHash)
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL, Validators{})
+
+	assert.ErrorContains(t, err, "503")
+	require.NotNil(t, page)
+	assert.Equal(t, http.StatusServiceUnavailable, page.StatusCode)
+	assert.False(t, page.NotModified)
+}